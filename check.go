@@ -0,0 +1,162 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+// Checking the output of examples against that expected, as in testdata.
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"robpike.io/ivy/config"
+	"robpike.io/ivy/exec"
+	"robpike.io/ivy/run"
+)
+
+// A checkBlock is an example in a check file: input and the output it should produce.
+type checkBlock struct {
+	line   int // Line number of the first line of input.
+	input  []string
+	output []string
+}
+
+// checkBlocks returns the examples in the text of a check file.
+// The format is described in testdata/README.
+func checkBlocks(text string) []checkBlock {
+	lines := strings.Split(text, "\n")
+	// Will have a trailing empty string.
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	var blocks []checkBlock
+	lineNum := 1
+	for len(lines) > 0 {
+		// Skip blank and initial comment lines.
+		for len(lines) > 0 && (len(lines[0]) == 0 || strings.HasPrefix(lines[0], "#")) {
+			lines = lines[1:]
+			lineNum++
+		}
+		if len(lines) == 0 {
+			break
+		}
+		block := checkBlock{line: lineNum}
+
+		// Input ends at tab-indented line.
+		for len(lines) > 0 {
+			line := strings.TrimRight(lines[0], " \t")
+			if strings.HasPrefix(line, "\t") {
+				break
+			}
+			block.input = append(block.input, line)
+			lines = lines[1:]
+			lineNum++
+		}
+
+		// Output ends at non-blank, non-tab-indented line.
+		// Indented "#" is expected blank line in output.
+		for len(lines) > 0 {
+			line := strings.TrimRight(lines[0], " \t")
+			if line != "" && !strings.HasPrefix(line, "\t") {
+				break
+			}
+			block.output = append(block.output, strings.TrimPrefix(line, "\t"))
+			lines = lines[1:]
+			lineNum++
+		}
+		for len(block.output) > 0 && block.output[len(block.output)-1] == "" {
+			block.output = block.output[:len(block.output)-1]
+		}
+		for i, line := range block.output {
+			if line == "#" {
+				block.output[i] = ""
+			}
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// checkFile runs each example in the named check file in a new context
+// and reports to w each one whose output differs from that expected.
+// If the file name ends in _fail.ivy, each example is instead expected
+// to fail. It returns false if the file could not be read or any
+// example did not behave as expected.
+func checkFile(conf *config.Config, file string, w io.Writer) bool {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ivy: %s\n", err)
+		return false
+	}
+	shouldFail := strings.HasSuffix(file, "_fail.ivy")
+	fileConf := *conf
+	ok := true
+	for _, block := range checkBlocks(string(data)) {
+		resetConfig(&fileConf, conf)
+		in := strings.Join(block.input, "\n")
+		stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+		run.Ivy(exec.NewContext(&fileConf), in, stdout, stderr)
+		switch {
+		case shouldFail:
+			if stderr.Len() == 0 {
+				fmt.Fprintf(w, "%s:%d: expected execution failure:\n\t%s\n", file, block.line, strings.Join(block.input, "\n\t"))
+				ok = false
+			}
+		case stderr.Len() != 0:
+			fmt.Fprintf(w, "%s:%d: execution failure (%s):\n\t%s\n", file, block.line, strings.TrimSuffix(stderr.String(), "\n"), strings.Join(block.input, "\n\t"))
+			ok = false
+		default:
+			result := strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+			if !equal(result, block.output) {
+				fmt.Fprintf(w, "%s:%d:\n\t%s\ngot:\n\t%s\nwant:\n\t%s\n",
+					file, block.line,
+					strings.Join(block.input, "\n\t"),
+					strings.Join(result, "\n\t"),
+					strings.Join(block.output, "\n\t"))
+				ok = false
+			}
+		}
+	}
+	return ok
+}
+
+// resetConfig sets the settings of conf that each example starts with to
+// those of base, with the random seed 0, as the tests do. Other settings,
+// such as debugging flags, carry over from one example to the next.
+func resetConfig(conf, base *config.Config) {
+	conf.SetFormat(base.Format())
+	conf.SetFloatPrec(base.FloatPrec())
+	conf.SetMaxBits(base.MaxBits())
+	conf.SetMaxDigits(base.MaxDigits())
+	conf.SetOrigin(base.Origin())
+	conf.SetPrompt(base.Prompt())
+	conf.SetBase(base.Base())
+	conf.SetRandomSeed(0)
+	conf.SetGlyphs(base.Glyphs())
+	conf.SetModulus(base.Modulus())
+	conf.SetMachine(base.Machine())
+	conf.SetSandbox(base.Sandbox())
+}
+
+// equal reports whether the lines of output match those expected,
+// ignoring leading and trailing blanks.
+func equal(a, b []string) bool {
+	// Split leaves an empty trailing line.
+	if len(a) > 0 && a[len(a)-1] == "" {
+		a = a[:len(a)-1]
+	}
+	if len(a) != len(b) {
+		return false
+	}
+	for i, s := range a {
+		if strings.TrimSpace(s) != strings.TrimSpace(b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -32,7 +32,7 @@ type Config struct {
 	errOutput   io.Writer
 	format      string
 	ratFormat   string
-	formatVerb  byte // The verb if format is floating-point.
+	formatVerb  byte // The verb if format is floating-point or exact.
 	formatPrec  int  // The precision if format is floating-point.
 	formatFloat bool // Whether format is floating-point.
 	origin      int
@@ -51,7 +51,13 @@ type Config struct {
 	// Bases: 0 means C-like, base 10 with 07 for octal and 0xa for hex.
 	inputBase  int
 	outputBase int
-	mobile     bool // Running on a mobile platform.
+	modulus    *big.Int // Modulus for integer arithmetic; nil means none.
+	machine    bool     // Use machine (float64 and int64) arithmetic.
+	mobile     bool     // Running on a mobile platform.
+	glyphs     bool     // Display op definitions using APL glyphs.
+	json       bool     // Print results as JSON, one per line.
+	sandbox    bool     // Forbid file access from ivy programs.
+	log        *Log     // Transcript of the session; nil if none.
 }
 
 func (c *Config) init() {
@@ -123,6 +129,10 @@ func (c *Config) SetFormat(s string) {
 	switch s[len(s)-1] {
 	case 'f', 'F', 'g', 'G', 'e', 'E':
 		// Yes
+	case 'r', 'm':
+		// No, one of ivy's formats for exact values.
+		c.formatVerb = s[len(s)-1]
+		return
 	default:
 		return
 	}
@@ -144,6 +154,17 @@ func (c *Config) FloatFormat() (verb byte, prec int, ok bool) {
 	return c.formatVerb, c.formatPrec, c.formatFloat
 }
 
+// ExactFormat returns the verb of the format if it is one of ivy's own
+// formats for exact values: 'r' prints a rational as a decimal with the
+// repeating part in parentheses, 0.1(6), and 'm' as a mixed number, 1 1/2.
+// Otherwise it returns 0.
+func (c *Config) ExactFormat() byte {
+	if c.formatFloat {
+		return 0
+	}
+	return c.formatVerb
+}
+
 // Debug returns the value of the specified boolean debugging flag.
 func (c *Config) Debug(flag string) bool {
 	for i, f := range DebugFlags {
@@ -184,6 +205,34 @@ func (c *Config) SetOrigin(origin int) {
 	c.bigOrigin = big.NewInt(int64(origin))
 }
 
+// Modulus returns the modulus for integer arithmetic,
+// or nil if arithmetic is not modular.
+func (c *Config) Modulus() *big.Int {
+	return c.modulus
+}
+
+// SetModulus sets the modulus for integer arithmetic.
+// A nil or zero modulus turns modular arithmetic off.
+func (c *Config) SetModulus(m *big.Int) {
+	c.init()
+	if m != nil && m.Sign() == 0 {
+		m = nil
+	}
+	c.modulus = m
+}
+
+// Machine reports whether arithmetic uses fixed-size machine
+// types: float64 for floating point and wrapping int64 for integers.
+func (c *Config) Machine() bool {
+	return c.machine
+}
+
+// SetMachine sets whether arithmetic uses fixed-size machine types.
+func (c *Config) SetMachine(machine bool) {
+	c.init()
+	c.machine = machine
+}
+
 // Prompt returns the interactive prompt.
 func (c *Config) Prompt() string {
 	return c.prompt
@@ -348,3 +397,48 @@ func (c *Config) SetMobile(mobile bool) {
 	c.init()
 	c.mobile = mobile
 }
+
+// Glyphs reports whether op definitions are displayed using APL glyphs.
+func (c *Config) Glyphs() bool {
+	return c.glyphs
+}
+
+// SetGlyphs sets whether op definitions are displayed using APL glyphs.
+func (c *Config) SetGlyphs(glyphs bool) {
+	c.init()
+	c.glyphs = glyphs
+}
+
+// JSON reports whether printed results are encoded as JSON.
+func (c *Config) JSON() bool {
+	return c.json
+}
+
+// SetJSON sets whether printed results are encoded as JSON.
+func (c *Config) SetJSON(json bool) {
+	c.init()
+	c.json = json
+}
+
+// Sandbox reports whether ivy programs are forbidden to access files.
+func (c *Config) Sandbox() bool {
+	return c.sandbox
+}
+
+// SetSandbox sets whether ivy programs are forbidden to access files.
+func (c *Config) SetSandbox(sandbox bool) {
+	c.init()
+	c.sandbox = sandbox
+}
+
+// Log returns the transcript being kept of the session, or nil if none.
+func (c *Config) Log() *Log {
+	return c.log
+}
+
+// SetLog sets the transcript to keep of the session; nil stops keeping one.
+// The previous transcript, if any, is not closed.
+func (c *Config) SetLog(log *Log) {
+	c.init()
+	c.log = log
+}
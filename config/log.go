@@ -0,0 +1,150 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+/*
+A log is a text file holding one record per line of input, output or error
+output. Each record is the time, a mark giving the kind of line, and the
+text of the line, separated by single spaces:
+	2022-05-01T10:30:00.000+10:00 < x = 1 2 3
+	2022-05-01T10:30:00.000+10:00 < +/x
+	2022-05-01T10:30:00.000+10:00 > 6
+	2022-05-01T10:30:02.000+10:00 < 1/0
+	2022-05-01T10:30:02.000+10:00 ! division by zero
+*/
+
+// The marks for the kinds of record in a log.
+const (
+	LogInput  = '<'
+	LogOutput = '>'
+	LogError  = '!'
+)
+
+const logTime = "2006-01-02T15:04:05.000Z07:00"
+
+// A Log records a transcript of a session: each line of input, output
+// and error output, with the time it was written.
+type Log struct {
+	name      string
+	w         io.WriteCloser
+	err       error // The first error writing to w.
+	output    logWriter
+	errOutput logWriter
+}
+
+// CreateLog creates the named file and returns a Log that writes to it.
+func CreateLog(name string) (*Log, error) {
+	fd, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	l := &Log{
+		name: name,
+		w:    fd,
+	}
+	l.output = logWriter{log: l, kind: LogOutput}
+	l.errOutput = logWriter{log: l, kind: LogError}
+	return l, nil
+}
+
+// Name returns the name of the log file.
+func (l *Log) Name() string {
+	return l.name
+}
+
+// Input records the line of input, which should not include the newline.
+func (l *Log) Input(line string) {
+	l.record(LogInput, line)
+}
+
+// Output returns a writer that records the lines written to it as output.
+// Its writes always succeed; errors are reported by Close.
+func (l *Log) Output() io.Writer {
+	return &l.output
+}
+
+// ErrOutput returns a writer that records the lines written to it as
+// error output. Its writes always succeed; errors are reported by Close.
+func (l *Log) ErrOutput() io.Writer {
+	return &l.errOutput
+}
+
+// Close records any incomplete lines of output and closes the file.
+// It returns the first error writing or closing the file.
+func (l *Log) Close() error {
+	l.output.flush()
+	l.errOutput.flush()
+	if err := l.w.Close(); l.err == nil {
+		l.err = err
+	}
+	err := l.err
+	if l.err == nil {
+		l.err = os.ErrClosed
+	}
+	return err
+}
+
+// record writes a record of the line to the file.
+func (l *Log) record(kind byte, line string) {
+	if l.err != nil {
+		return
+	}
+	_, l.err = fmt.Fprintf(l.w, "%s %c %s\n", time.Now().Format(logTime), kind, line)
+}
+
+// ParseLog returns the kind and text of a record in a log.
+// It reports whether the line is a valid record.
+func ParseLog(record string) (kind byte, text string, ok bool) {
+	f := strings.SplitN(record, " ", 3)
+	if len(f) < 3 || len(f[1]) != 1 {
+		return 0, "", false
+	}
+	if _, err := time.Parse(logTime, f[0]); err != nil {
+		return 0, "", false
+	}
+	switch kind = f[1][0]; kind {
+	case LogInput, LogOutput, LogError:
+		return kind, f[2], true
+	}
+	return 0, "", false
+}
+
+// logWriter is an io.Writer that records the lines written to it
+// in a Log. It holds an incomplete line until its newline arrives.
+type logWriter struct {
+	log  *Log
+	kind byte
+	line []byte
+}
+
+func (w *logWriter) Write(b []byte) (int, error) {
+	w.line = append(w.line, b...)
+	for {
+		i := bytes.IndexByte(w.line, '\n')
+		if i < 0 {
+			break
+		}
+		w.log.record(w.kind, string(w.line[:i]))
+		w.line = w.line[i+1:]
+	}
+	return len(b), nil
+}
+
+// flush records the incomplete line, if any.
+func (w *logWriter) flush() {
+	if len(w.line) > 0 {
+		w.log.record(w.kind, string(w.line))
+		w.line = nil
+	}
+}
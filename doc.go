@@ -19,7 +19,57 @@ floating point values (1e3, -1.5 (representing 1000 and -3/2)).
 Some functions such as sqrt are irrational. When ivy evaluates an irrational
 function, the result is stored in a high-precision floating-point number (default
 256 bits of mantissa). Thus when using irrational functions, the values have high
-precision but are not exact.
+precision but are not exact. When the answer happens to be rational, however,
+as in sqrt 4/9, 27**1/3 or 4 log 8, sqrt, ** and log find it and the result
+is exact.
+
+To go back from a float to a fraction, cf gives the terms of a continued
+fraction, stopping for a float when they reproduce it, and uncf turns the
+terms into an exact rational: uncf 5 cf pi is 103993/33102. A bestrat B gives
+the nearest rational to B with denominator at most A, so 1000 bestrat pi is
+355/113.
+
+Since vectors cannot nest, factor of a vector gives a matrix with a row of factors
+for each element, padded at the end with 1s so each row's product is the element.
+
+When speed matters more than precision, the machine command (see below)
+switches to fixed-size arithmetic: non-integral results are IEEE 754 float64
+values and integer +, -, * and ** wrap around at 64 bits. Float64 values sit
+between rationals and floats in the type hierarchy, so mixing one with an
+exact value gives a float64 while mixing it with a float gives a float, and
+unary float converts a float64 to a float exactly. Outside machine mode, uncf cf
+turns a float64 back into the simplest fraction that rounds to it. In machine
+mode, complex results have float64 parts and are computed with complex128
+arithmetic, and vector results whose elements are all 64-bit integers or
+float64 values are stored as plain arrays of them, on which elementwise
+arithmetic, comparison and reduction run without per-element overhead.
+
+To bound rounding error, A interval B creates the closed interval [A, B] and
+unary interval gives the smallest interval containing its argument. Arithmetic
+and sqrt on intervals round outward, so the true result always lies within the
+printed bounds; lower and upper extract them. The other elementary functions,
+such as log, exp and sin, compute their bounds with extra precision and widen
+them by a generous margin, but that margin is not proven, so their bounds are
+not guaranteed. Comparing intervals gives 1 or 0 when the answer is certain and
+the interval [0, 1] when it is not; certainly and possibly turn such a result
+into 0 or 1.
+
+Unary json encodes any value losslessly as a JSON string, and unjson decodes
+one: a rational becomes an object {"num": 1, "den": 3}, a float an object holding
+its shortest exact digits and precision, and a matrix an object holding its shape
+and data in row-major order. Chars and strings become JSON strings. Any JSON number
+decodes exactly, so unjson '0.1' is the rational 1/10. The -json flag prints each
+result as a line of JSON rather than as text.
+
+Programs can read and write files: read B gives the contents of the file named B,
+lines B its lines, and dir B the names in a directory, while A write B and A append B
+store the text A. Relative names are found as by the )get command, and the name "-"
+means standard input or output. Since vectors cannot nest, lines and dir return char
+matrices padded with blanks; write and append accept a char matrix, writing each row
+as a line with trailing blanks removed, and return the number of bytes written. The
+sandbox setting forbids file access; see the )sandbox command. As operators, read,
+lines, dir, write and append cannot be used as variable names; programs written for
+earlier versions that assign to them must rename those variables.
 
 Unlike in most other languages, operators always have the same precedence and
 expressions are evaluated in right-associative order. That is, unary operators
@@ -45,9 +95,30 @@ assigned with the = operator. Assignment is an expression.
 After each successful expression evaluation, the result is stored in the variable
 called _ (underscore) so it can be used in the next expression.
 
+Run-time errors can be trapped with the catch keyword. In the expression
+x catch y, the operand x is evaluated and, if that raises an error, y is
+evaluated instead to provide the result. The text of the error message is
+stored as a char vector in the variable _error. Thus (1 / 0) catch 0 is 0,
+and inside a user-defined operator (float solve x) catch _error returns
+either the solution or the reason it failed.
+
+The assert keyword checks that every element of the value of the expression
+that follows it is non-zero, and otherwise raises an error showing the text of
+the expression and its value or, if it is a comparison, the values of both its
+operands: assert 6 == double 3. A char value is an error. The test special
+command runs the user-defined operators that are tests.
+
 The APL operators, adapted from https://en.wikipedia.org/wiki/APL_syntax_and_symbols,
 and their correspondence are listed here. The correspondence is incomplete and inexact.
 
+The APL glyphs in these tables may be typed in place of the ivy names: ⍳5 is
+iota 5, 2×⍳3 is 2*iota 3, and (⍳3)∘.×⍳3 is (iota 3) o.* iota 3. Where APL uses
+one glyph for two operators that ivy names differently, such as ⌈ for ceil and max,
+the glyph's meaning depends on whether it is used as a unary or binary operator.
+A glyph listed in only one table, such as ∣ for abs, is an error in the other
+role; APL's binary ∣ is residue, but with its operands swapped from mod.
+A number may be written with APL's high minus: ¯3 is -3 and 1e¯3 is 1/1000.
+
 Unary operators
 
 	Name              APL   Ivy     Meaning
@@ -93,6 +164,27 @@ Unary operators
 	Imaginary part    11○B  imag    Imaginary part of a complex number.
 	Phase angle       12○B  phase   Phase angle (argument) of a complex number.
 	Imaginary         0JB   j       Complex number with zero real part.
+	Gamma                   gamma   Gamma function; (B-1)! for a positive integer B
+	Log gamma               lgamma  Natural logarithm of abs(gamma B)
+	Error function          erf     erf(B)
+	Compl. error fn         erfc    1 - erf(B), accurate when B is large
+	Riemann zeta            zeta    ζ(B); exact for integers B <= 0
+	Is prime                isprime 1 if B is prime, otherwise 0 (integer only)
+	Next prime              nextprime The least prime greater than B (integer only)
+	Prime factors           factor  Prime factors of B in increasing order (matrix for vector B)
+	Euler's totient         totient Count of integers 1 to B relatively prime to B
+	Integer square root     isqrt   Greatest integer whose square is <= B
+	Continued fraction      cf      Terms of the continued fraction of B
+	From continued fraction uncf    Exact value of the continued fraction with terms B
+	Interval                interval Smallest interval containing B
+	Lower bound             lower   Lower bound of interval B
+	Upper bound             upper   Upper bound of interval B
+	Certainly               certainly 1 if every value in B is nonzero, otherwise 0
+	Possibly                possibly 1 if some value in B is nonzero, otherwise 0
+	Read file               read    Contents of the file named B, as a string
+	Read lines              lines   Lines of the file named B, as rows of a char matrix
+	List directory          dir     Names in the directory B, as rows of a char matrix
+	Exit                    exit    Stop the program with exit status B, from 0 to 255
 
 Binary operators
 
@@ -150,6 +242,20 @@ Binary operators
 	Bitwise xor                 ^       Bitwise A exclusive or B (integer only)
 	Left shift                  <<      A shifted left B bits (integer only)
 	Right Shift                 >>      A shifted right B bits (integer only)
+	Beta                        beta    Beta function: gamma(A) * gamma(B) / gamma(A+B)
+	Bessel J                    besselj Bessel function of the first kind of order A at B
+	Bessel Y                    bessely Bessel function of the second kind of order A at B
+	Greatest common divisor     gcd     Greatest common divisor of A and B (APL's ∨ on integers)
+	Least common multiple       lcm     Least common multiple of A and B (APL's ∧ on integers)
+	Modular inverse             modinv  The X in 0 to B-1 for which A*X is 1 modulo B
+	Modular power               powmod  A**E modulo M, where B is the vector E M
+	Integer root                iroot   Greatest integer whose Ath power is <= B
+	Jacobi symbol               jacobi  Jacobi symbol (A/B) for odd positive B
+	Continued fraction          cf      The first A terms of the continued fraction of B
+	Best rational approximation bestrat Nearest rational to B with denominator at most A
+	Interval                    interval The interval of values from A to B
+	Write file                  write   Write the text A to the file named B
+	Append to file              append  Append the text A to the file named B
 
 Operators and axis indicator
 
@@ -169,12 +275,21 @@ Type-converting operations
 	Code                    code B  The integer Unicode value of char B
 	Char                    char B  The character with integer Unicode value B
 	Float                   float B The floating-point representation of B
+	JSON                    json B  The JSON encoding of B, as a string
+	Unjson                  unjson B The value encoded by the JSON string B
 
 Pre-defined constants
 
-The constants e (base of natural logarithms) and pi (π) are pre-defined to high
-precision, about 3000 decimal digits truncated according to the floating point
-precision setting.
+The constants e (base of natural logarithms), pi (π), euler (the Euler-Mascheroni
+constant γ), phi (the golden ratio φ), and catalan (Catalan's constant) are pre-defined.
+They are computed as needed to the full floating point precision setting, however high.
+Only e and pi are reserved. The others are values only until a variable of the same
+name is assigned, which then takes their place until it is erased.
+
+The special functions gamma, lgamma, erf, erfc, zeta, beta, besselj and bessely
+are also computed to the full precision. All but the order of a Bessel function
+may be complex. Real arguments give real results; lgamma of a real B is the
+logarithm of the magnitude of gamma B.
 
 Character data
 
@@ -242,12 +357,12 @@ Example: multiline operator definition (binary):
 	iota 3 sum 4
 	result: 1 2 3 4 5 6 7
 
-Example: primes less than N (unary):
+Example: primes less than N (unary; isprime is much faster):
 	op primes N = (not T in T o.* T) sel T = 1 drop iota N
 	primes 50
 	result: 2 3 5 7 11 13 17 19 23 29 31 37 41 43 47
 
-Example: greatest common divisor (binary):
+Example: greatest common divisor (binary; a slow version of the built-in gcd):
 	op a gcd b =
 		a == b: a
 		a > b: b gcd a-b
@@ -285,6 +400,49 @@ To write to a global without reading it first, insert an unused read.
 	total last
 	result: 12 3
 
+When standard input is a terminal, ivy provides line editing with the usual
+Emacs-style keys. The up and down arrows step through the history of input
+lines, which is kept between sessions in the file named by $IVYHISTORY,
+by default ~/.ivy_history. The tab key completes the names of operators,
+variables and, after a right paren, special commands. The lines of a
+multi-line op definition are saved as a single history entry, so recalling
+it brings back the whole definition, with ↵ marking the line breaks.
+
+At startup, ivy runs the file named by $IVYRC, or if that is not set the file
+~/.ivyrc if it exists, unless the -norc flag is given. An error in that file
+is reported and skips the rest of it, but ivy still starts. A relative file
+name given to the -f flag or the )get command that does not exist in the
+current directory is looked up in the directories listed in $IVYPATH, which
+are separated by colons. The )lib command loads a library from that path.
+
+Arguments on the command line after -- are passed to the program, not run as
+files, in the variable args: a char matrix with one argument per row, padded
+with blanks to the length of the longest, so ivy args[1] is the value of a
+numeric first argument. If the first file begins with #!, the remaining
+arguments are passed to it without the --, so a file starting with the line
+#!/usr/bin/env ivy can be executed directly. The unary operator exit stops the
+program with the given status.
+
+Ivy can also process standard input like awk. With the -each flag, ivy runs the
+given expression once for each line of input, with the variable x set to the
+numbers on the line (a scalar or vector) or, if it is not all numbers, its text,
+and nr set to the line number. The -begin and -end expressions run before and
+after the input, so they can accumulate across lines:
+	seq 1 100 | ivy -begin 's=0' -each 's=s+x' -end 's'
+With the -all flag, x is instead set to all of standard input before -e runs: a
+matrix with a row of numbers per line, a vector if there is one number per line,
+or otherwise a char matrix of the lines, so seq 1 100 | ivy -all -e '+/x' is 5050.
+The -all flag requires -e and cannot be used with -each.
+
+To test ivy programs, ivy -check file.ivy ... runs the examples in the files and
+reports each whose output differs from that expected, exiting with non-zero status
+if any does. Examples are separated by blank lines; each is lines of input followed
+by the expected output, every line indented by a tab, with a tab and # for a blank
+line. Each example runs in a new workspace, starting with the settings given by
+flags, so it should load any library it needs. In a file whose name ends in
+_fail.ivy, every example must fail instead. This is the format of ivy's own
+tests; see testdata/README.
+
 Special commands
 
 Ivy accepts a number of special commands, introduced by a right paren
@@ -301,9 +459,24 @@ base 10 and must be non-negative on input.
 		respectively.  Base 0 allows C-style input: decimal, with 037 being
 		octal and 0x10 being hexadecimal. If the base is greater than 10,
 		any identifier formed from valid numerals in the base system, such
-		as abe for base 16, is taken to be a number. TODO: To output
-		large integers and rationals, base must be one of 0 2 8 10 16.
-		Floats are always printed base 10.
+		as abe for base 16, is taken to be a number. A number in the
+		input base may have a fraction, as in 0.1 for 1/3 in base 3, but
+		its exponent, if any, is a decimal power of ten. Floats print in
+		the output base, correctly rounded; where the number is very large
+		or small, d.ddd@e means d.ddd times the base to the (decimal) power e.
+	) clear
+		Remove all user-defined ops and variables, leaving the
+		configuration unchanged.
+	) copy "save.ivy" name ...
+		Read the workspace saved in the named file and copy only the
+		named ops and variables from it, leaving the configuration and
+		everything else unchanged. With no names, copy all of them.
+		An op that calls other ops works only if they are copied too.
+		(Unimplemented on mobile.)
+	) checkpoint name
+		Record the variables, user-defined operators and settings under
+		the name, for rollback and diff. Values are shared, not copied,
+		so a checkpoint is cheap. With no argument, list the checkpoints.
 	) cpu
 		Print the duration of the last interactive calculation.
 	) debug name 0|1
@@ -312,15 +485,81 @@ base 10 and must be non-negative on input.
 	) demo
 		Run a line-by-line interactive demo. On mobile platforms,
 		use the Demo menu option instead.
+	) diff name [name]
+		List the variables, user-defined operators and settings that
+		changed between the two named checkpoints or, if only one is
+		named, between it and the current state.
+	) erase name ...
+		Remove the named variables and user-defined ops.
+	) erase op f
+	) erase op f x
+	) erase op x f y
+		Remove only the user-defined op f, never a variable. As in the
+		op declaration, f x names only the unary form and x f y only
+		the binary form; the argument names are ignored.
+	) export "data.csv" name [header [starts]]
+		Write the matrix (or vector) in the variable name to the named
+		file, one record per row, as comma-separated values, or as
+		tab-separated values if the file name ends in .tsv. Numbers are
+		written exactly: terminating rationals as decimals, others as a/b.
+		If a header variable is named, its rows are written first, as
+		the header record; "" names no header. If a starts variable is
+		named, it holds the index of the first column of each field,
+		as set by import; otherwise each run of chars in a row is one
+		field. Text is written without trailing blanks. Every record
+		must have the same number of fields. (Unimplemented on mobile.)
 	) format ""
 		Set the format for printing values. If empty, the output is printed
 		using the output base. If non-empty, the format determines the
 		base used in printing. The format is in the style of golang.org/pkg/fmt.
 		For floating-point formats, flags and width are ignored.
+		Two formats are ivy's own, for exact values: "%r" prints rationals
+		as decimals with the repeating part in parentheses, 0.1(6), and
+		"%m" prints them as mixed numbers, 1 1/2; in vectors and matrices,
+		where a blank separates elements, the parts are joined by their
+		sign, 1+1/2. Numbers in files read by import and in stream input
+		may be written as repeating decimals: 0.1(6) is 1/6.
+	) glyphs 0
+		If set, show the definitions of user-defined operators, and the
+		expressions quoted in error messages such as a failed assert,
+		using APL glyphs for the built-in operators (see the op command).
 	) get "save.ivy"
 		Read input from the named file; return to interactive execution
 		afterwards. If no file is specified, read from "save.ivy".
 		(Unimplemented on mobile.)
+	) import "data.csv" name [header [starts]]
+		Read the named comma-separated (or, for .tsv, tab-separated)
+		file, found as for the get command, into the variable name as a
+		matrix with one row per record. Quoted fields are supported. A
+		column whose fields all parse as ivy numbers holds their exact
+		values, so decimals become rationals, and its empty fields are
+		missing values that become 0; any other column becomes
+		as many columns of chars as its widest field, padded with blanks.
+		If a header variable is named, the first record is stored in it
+		as a char matrix with one row per field; "" names no header. If
+		a starts variable is named, it is set to the index of the first
+		matrix column of each field, so (transp x)[starts[i]] is the
+		start of field i, and export with it writes the fields back
+		unchanged. Indexes are in the current origin.
+		(Unimplemented on mobile.)
+	) lib name
+		Read the library file name.ivy, found as for the get command,
+		unless it has already been loaded; a library whose loading failed
+		is read again. The name may be an identifier or a quoted string.
+		With no argument, list the loaded libraries.
+		(Unimplemented on mobile.)
+	) log "session.log"
+		Record each line of input and of output and error output, with
+		the time, in the named file. ) log off stops recording; with no
+		argument, print the name of the file. The -log flag records the
+		whole session, and ivy -replay session.log executes the recorded
+		input and reports any output that differs from that recorded.
+		(Unimplemented on mobile.)
+	) machine 0
+		If set, compute using machine arithmetic: integers wrap around at
+		64 bits, numbers that would be rationals or floats, including
+		non-integral and very large number literals, are float64, and
+		complex numbers have float64 parts.
 	) maxbits 1e6
 		To avoid consuming too much memory, if an integer result would
 		require more than this many bits to store, abort the calculation.
@@ -332,6 +571,13 @@ base 10 and must be non-negative on input.
 	) maxstack 1e5
 		To avoid using too much stack, the number of nested active calls to
 		user-defined operators is limited to maxstack.
+	) modulus 0
+		If the modulus is non-zero, the arithmetic operators + - * / and **,
+		including their uses in inner and outer products, and unary - and /,
+		reduce every integer result to the range [0, modulus). Division
+		multiplies by the modular inverse, as does exponentiation to a
+		negative power. Other operators, and constants, are unaffected,
+		so indexes, shapes and counts such as iota 10 mean what they say.
 	) op X
 		If X is absent, list all user-defined operators. Otherwise,
 		show the definition of the user-defined operator X. Inside the
@@ -344,6 +590,20 @@ base 10 and must be non-negative on input.
 		The value is in bits. The exponent always has 32 bits.
 	) prompt ""
 		Set the interactive prompt.
+	) restore "save.snap"
+		Replace the workspace with the one in the named snapshot file,
+		written by the snapshot command. If no file is specified, restore
+		from "save.snap".
+		(Unimplemented on mobile.)
+	) rollback name
+		Return the variables, user-defined operators and settings to
+		their state at the named checkpoint, which remains available.
+	) sandbox 0
+		If set, the file operators read, lines, dir, write and append
+		fail for any file but "-", standard input or output, as do the
+		special commands that read or write files: copy, export, get,
+		import, lib, log, restore, save, snapshot and test with a file.
+		Once set, it cannot be cleared; the -sandbox flag sets it at startup.
 	) save "save.ivy"
 		Write definitions of user-defined operators and variables to the
 		named file, as ivy textual source. If no file is specified, save to
@@ -351,6 +611,27 @@ base 10 and must be non-negative on input.
 		(Unimplemented on mobile.)
 	) seed 0
 		Set the seed for the ? operator.
+	) snapshot "save.snap"
+		Write the configuration, user-defined operators and variables
+		to the named file in a binary format that, unlike save, holds
+		every value exactly, including the precision and full mantissa
+		of floats, and is quick to restore even for large matrices.
+		If no file is specified, write to "save.snap".
+		(Unimplemented on mobile.)
+	) test "file.ivy"
+		Read the named file, as with get, if one is given, then run each
+		unary user-defined operator whose name begins with test, in the
+		order they were defined, applied to an empty vector. A test fails
+		if it raises an error, for instance with assert. Print the result
+		and time of each test and then the numbers passed and failed,
+		which is an error if any failed.
+	) undo
+		Return the variables, user-defined operators and settings to
+		their state before the previous line of input. Since the undo
+		is itself a line of input, a second undo cancels the first.
+	) vars
+		List the variables with their types and, for vectors and
+		matrices, their shapes.
 
 */
 package main
@@ -0,0 +1,417 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package edit implements a small line editor for interactive ivy
+// sessions: Emacs-style editing keys, a history that persists between
+// sessions, and tab completion. It talks to the terminal directly and
+// has no knowledge of ivy itself; the caller supplies completions.
+package edit // import "robpike.io/ivy/edit"
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInterrupt is returned by ReadLine when the user types control-C.
+var ErrInterrupt = errors.New("interrupt")
+
+// Key codes.
+const (
+	ctrlA     = 1
+	ctrlB     = 2
+	ctrlC     = 3
+	ctrlD     = 4
+	ctrlE     = 5
+	ctrlF     = 6
+	ctrlH     = 8
+	tab       = 9
+	ctrlJ     = 10
+	ctrlK     = 11
+	ctrlL     = 12
+	ctrlM     = 13
+	ctrlN     = 14
+	ctrlP     = 16
+	ctrlU     = 21
+	ctrlW     = 23
+	esc       = 27
+	backspace = 127
+)
+
+// Editor reads lines from a terminal.
+type Editor struct {
+	in  *os.File
+	out *os.File
+
+	// Complete, if not nil, is called when the user types tab.
+	// It is given the line and the cursor position, counted in runes,
+	// and returns the start of the word being completed and the
+	// possible completions for it.
+	Complete func(line []rune, pos int) (start int, candidates []string)
+
+	history  []string
+	histFile string
+
+	// State of the line being edited.
+	prompt  []rune
+	buf     []rune
+	pos     int    // Cursor position in buf.
+	offset  int    // Index of the first visible rune of buf.
+	histPos int    // Index in history of the line being edited.
+	saved   []rune // The new line, saved while browsing history.
+	lastTab bool   // Previous key was a tab that completed nothing.
+	pending []byte // Input read but not yet processed.
+	readBuf [64]byte
+}
+
+// New returns an Editor that reads from in and echoes to out.
+// It returns an error if either is not a terminal.
+func New(in, out *os.File) (*Editor, error) {
+	if !isTerminal(in) || !isTerminal(out) {
+		return nil, errors.New("not a terminal")
+	}
+	return &Editor{
+		in:  in,
+		out: out,
+	}, nil
+}
+
+// ReadLine reads a line, showing the prompt. The returned line has no
+// trailing newline, but it may contain newlines if it is a multi-line
+// entry recalled from history. At end of file, ReadLine returns io.EOF.
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	state, err := makeRaw(e.in)
+	if err != nil {
+		return "", err
+	}
+	defer restore(e.in, state)
+	e.prompt = []rune(prompt)
+	e.buf = e.buf[:0]
+	e.pos = 0
+	e.offset = 0
+	e.histPos = len(e.history)
+	e.saved = nil
+	e.lastTab = false
+	e.refresh()
+	for {
+		r, err := e.readRune()
+		if err != nil {
+			e.write("\r\n")
+			return "", err
+		}
+		wasTab := e.lastTab
+		e.lastTab = false
+		switch r {
+		case ctrlM, ctrlJ:
+			e.pos = len(e.buf)
+			e.refresh()
+			e.write("\r\n")
+			return string(e.buf), nil
+		case ctrlC:
+			e.write("^C\r\n")
+			return "", ErrInterrupt
+		case ctrlD:
+			if len(e.buf) == 0 {
+				e.write("\r\n")
+				return "", io.EOF
+			}
+			e.deleteRunes(e.pos, e.pos+1)
+		case ctrlA:
+			e.pos = 0
+		case ctrlE:
+			e.pos = len(e.buf)
+		case ctrlB:
+			e.left()
+		case ctrlF:
+			e.right()
+		case ctrlH, backspace:
+			if e.pos > 0 {
+				e.deleteRunes(e.pos-1, e.pos)
+				e.pos--
+			}
+		case ctrlK:
+			e.deleteRunes(e.pos, len(e.buf))
+		case ctrlU:
+			e.deleteRunes(0, e.pos)
+			e.pos = 0
+		case ctrlW:
+			start := e.wordStart()
+			e.deleteRunes(start, e.pos)
+			e.pos = start
+		case ctrlL:
+			e.write("\x1b[H\x1b[2J")
+		case ctrlP:
+			e.historyMove(-1)
+		case ctrlN:
+			e.historyMove(1)
+		case tab:
+			e.complete(wasTab)
+		case esc:
+			e.escape()
+		default:
+			if r >= ' ' {
+				e.insert(r)
+			}
+		}
+		e.refresh()
+	}
+}
+
+// escape handles an escape sequence, such as those sent by the arrow keys.
+func (e *Editor) escape() {
+	r, err := e.readRune()
+	if err != nil {
+		return
+	}
+	switch r {
+	case 'b':
+		e.pos = e.wordStart()
+		return
+	case 'f':
+		e.pos = e.wordEnd()
+		return
+	case '[', 'O':
+	default:
+		return
+	}
+	// Read the parameters and the final byte of the control sequence.
+	var param []rune
+	for {
+		r, err = e.readRune()
+		if err != nil {
+			return
+		}
+		if r < '0' || '?' < r {
+			break
+		}
+		param = append(param, r)
+	}
+	switch r {
+	case 'A':
+		e.historyMove(-1)
+	case 'B':
+		e.historyMove(1)
+	case 'C':
+		e.right()
+	case 'D':
+		e.left()
+	case 'H':
+		e.pos = 0
+	case 'F':
+		e.pos = len(e.buf)
+	case '~':
+		switch string(param) {
+		case "1", "7":
+			e.pos = 0
+		case "4", "8":
+			e.pos = len(e.buf)
+		case "3":
+			e.deleteRunes(e.pos, e.pos+1)
+		}
+	}
+}
+
+// readRune returns the next rune typed at the terminal.
+func (e *Editor) readRune() (rune, error) {
+	for !utf8.FullRune(e.pending) {
+		n, err := e.in.Read(e.readBuf[:])
+		if n == 0 {
+			if err == nil {
+				err = io.EOF
+			}
+			return 0, err
+		}
+		e.pending = append(e.pending, e.readBuf[:n]...)
+	}
+	r, size := utf8.DecodeRune(e.pending)
+	e.pending = e.pending[size:]
+	return r, nil
+}
+
+func (e *Editor) insert(r rune) {
+	e.buf = append(e.buf, 0)
+	copy(e.buf[e.pos+1:], e.buf[e.pos:])
+	e.buf[e.pos] = r
+	e.pos++
+}
+
+// deleteRunes removes buf[start:end], clipped to the buffer.
+func (e *Editor) deleteRunes(start, end int) {
+	if end > len(e.buf) {
+		end = len(e.buf)
+	}
+	if start >= end {
+		return
+	}
+	e.buf = append(e.buf[:start], e.buf[end:]...)
+}
+
+func (e *Editor) left() {
+	if e.pos > 0 {
+		e.pos--
+	}
+}
+
+func (e *Editor) right() {
+	if e.pos < len(e.buf) {
+		e.pos++
+	}
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// wordStart returns the start of the word before the cursor.
+func (e *Editor) wordStart() int {
+	i := e.pos
+	for i > 0 && !isWordRune(e.buf[i-1]) {
+		i--
+	}
+	for i > 0 && isWordRune(e.buf[i-1]) {
+		i--
+	}
+	return i
+}
+
+// wordEnd returns the end of the word after the cursor.
+func (e *Editor) wordEnd() int {
+	i := e.pos
+	for i < len(e.buf) && !isWordRune(e.buf[i]) {
+		i++
+	}
+	for i < len(e.buf) && isWordRune(e.buf[i]) {
+		i++
+	}
+	return i
+}
+
+// historyMove replaces the line with the entry dir steps away in the history.
+func (e *Editor) historyMove(dir int) {
+	n := e.histPos + dir
+	if n < 0 || n > len(e.history) {
+		return
+	}
+	if e.histPos == len(e.history) {
+		e.saved = append(e.saved[:0], e.buf...)
+	}
+	e.histPos = n
+	if n == len(e.history) {
+		e.buf = append(e.buf[:0], e.saved...)
+	} else {
+		e.buf = append(e.buf[:0], []rune(e.history[n])...)
+	}
+	e.pos = len(e.buf)
+}
+
+// complete completes the word before the cursor. If the word is ambiguous,
+// the common prefix of the candidates is inserted; a second tab lists them.
+func (e *Editor) complete(list bool) {
+	if e.Complete == nil {
+		return
+	}
+	start, candidates := e.Complete(e.buf, e.pos)
+	if len(candidates) == 0 {
+		return
+	}
+	word := string(e.buf[start:e.pos])
+	prefix := candidates[0]
+	for _, c := range candidates[1:] {
+		for !strings.HasPrefix(c, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	if len(candidates) == 1 {
+		prefix += " "
+	}
+	e.lastTab = true
+	if len(prefix) > len(word) && strings.HasPrefix(prefix, word) {
+		for _, r := range prefix[len(word):] {
+			e.insert(r)
+		}
+		return
+	}
+	if !list {
+		return
+	}
+	sort.Strings(candidates)
+	e.write("\r\n" + columns(candidates, e.width()) + "\r\n")
+}
+
+// columns formats the words in columns to fit the width.
+func columns(words []string, width int) string {
+	colWidth := 0
+	for _, w := range words {
+		if n := utf8.RuneCountInString(w); n > colWidth {
+			colWidth = n
+		}
+	}
+	colWidth += 2
+	ncol := width / colWidth
+	if ncol < 1 {
+		ncol = 1
+	}
+	nrow := (len(words) + ncol - 1) / ncol
+	var b strings.Builder
+	for row := 0; row < nrow; row++ {
+		if row > 0 {
+			b.WriteString("\r\n")
+		}
+		for col := 0; col < ncol; col++ {
+			i := col*nrow + row
+			if i >= len(words) {
+				break
+			}
+			b.WriteString(words[i])
+			if col < ncol-1 && i+nrow < len(words) {
+				b.WriteString(strings.Repeat(" ", colWidth-utf8.RuneCountInString(words[i])))
+			}
+		}
+	}
+	return b.String()
+}
+
+// refresh redraws the line. A line too long for the terminal scrolls
+// horizontally to keep the cursor visible. Newlines in a recalled
+// multi-line entry are shown as ↵.
+func (e *Editor) refresh() {
+	avail := e.width() - len(e.prompt) - 1
+	if avail < 1 {
+		avail = 1
+	}
+	if e.pos < e.offset {
+		e.offset = e.pos
+	}
+	if e.pos > e.offset+avail {
+		e.offset = e.pos - avail
+	}
+	end := e.offset + avail
+	if end > len(e.buf) {
+		end = len(e.buf)
+	}
+	visible := strings.Replace(string(e.buf[e.offset:end]), "\n", "↵", -1)
+	s := "\r" + string(e.prompt) + visible + "\x1b[K\r"
+	if col := len(e.prompt) + e.pos - e.offset; col > 0 {
+		s += fmt.Sprintf("\x1b[%dC", col)
+	}
+	e.write(s)
+}
+
+func (e *Editor) write(s string) {
+	io.WriteString(e.out, s)
+}
+
+// width returns the width of the terminal.
+func (e *Editor) width() int {
+	if w := termWidth(e.out); w > 0 {
+		return w
+	}
+	return 80
+}
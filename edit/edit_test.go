@@ -0,0 +1,179 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package edit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var columnsTests = []struct {
+	words []string
+	width int
+	want  string
+}{
+	{[]string{"a", "bb", "ccc"}, 80, "a    bb   ccc"},
+	{[]string{"a", "bb", "ccc"}, 10, "a    ccc\r\nbb"},
+	{[]string{"a", "bb", "ccc"}, 3, "a\r\nbb\r\nccc"},
+	{[]string{"a", "b", "c", "d"}, 6, "a  c\r\nb  d"},
+	{[]string{"αβ", "γ"}, 80, "αβ  γ"},
+}
+
+func TestColumns(t *testing.T) {
+	for _, test := range columnsTests {
+		got := columns(test.words, test.width)
+		if got != test.want {
+			t.Errorf("columns(%q, %d) = %q; want %q", test.words, test.width, got, test.want)
+		}
+	}
+}
+
+var wordTests = []struct {
+	line       string
+	pos        int
+	start, end int
+}{
+	{"", 0, 0, 0},
+	{"abc def", 0, 0, 3},
+	{"abc def", 2, 0, 3},
+	{"abc def", 3, 0, 7},
+	{"abc def", 4, 0, 7},
+	{"abc def", 7, 4, 7},
+	{"x+y_1 ", 6, 2, 6},
+	{"+-+", 1, 0, 3},
+}
+
+func TestWord(t *testing.T) {
+	for _, test := range wordTests {
+		e := &Editor{buf: []rune(test.line), pos: test.pos}
+		if got := e.wordStart(); got != test.start {
+			t.Errorf("wordStart(%q, %d) = %d; want %d", test.line, test.pos, got, test.start)
+		}
+		if got := e.wordEnd(); got != test.end {
+			t.Errorf("wordEnd(%q, %d) = %d; want %d", test.line, test.pos, got, test.end)
+		}
+	}
+}
+
+var completeTests = []struct {
+	line       string
+	candidates []string
+	want       string
+}{
+	{"x = io", nil, "x = io"},
+	{"x = io", []string{"iota"}, "x = iota "},
+	{"x = s", []string{"sqrt", "sgn", "sin"}, "x = s"},
+	{"x = s", []string{"sin", "sinh"}, "x = sin"},
+	{"x = t", []string{"tan", "tanh", "text"}, "x = t"},
+}
+
+func TestComplete(t *testing.T) {
+	for _, test := range completeTests {
+		e := &Editor{buf: []rune(test.line), pos: len(test.line)}
+		e.Complete = func(line []rune, pos int) (int, []string) {
+			return e.wordStart(), test.candidates
+		}
+		e.complete(false)
+		if got := string(e.buf); got != test.want {
+			t.Errorf("complete %q with %q: got %q; want %q", test.line, test.candidates, got, test.want)
+		}
+	}
+}
+
+var historyTests = []string{
+	"1 2 3",
+	`'\n' is not a newline`,
+	`a\\b`,
+	"op f x =\n  x+1\n",
+	`trailing \`,
+}
+
+func TestHistory(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "history")
+	e := new(Editor)
+	if err := e.LoadHistory(file); err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range historyTests {
+		e.AddHistory(entry)
+	}
+	// Empty and repeated entries are not recorded.
+	e.AddHistory("  ")
+	e.AddHistory(historyTests[len(historyTests)-1])
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "\n"); n != len(historyTests) {
+		t.Errorf("history file has %d lines; want %d:\n%s", n, len(historyTests), data)
+	}
+	e = new(Editor)
+	if err := e.LoadHistory(file); err != nil {
+		t.Fatal(err)
+	}
+	if len(e.history) != len(historyTests) {
+		t.Fatalf("loaded %d entries; want %d", len(e.history), len(historyTests))
+	}
+	for i, entry := range e.history {
+		if entry != historyTests[i] {
+			t.Errorf("entry %d: got %q; want %q", i, entry, historyTests[i])
+		}
+	}
+}
+
+func TestHistoryTrim(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "history")
+	var b strings.Builder
+	for i := 0; i < MaxHistory+10; i++ {
+		b.WriteString(strings.Repeat("x", i%7+1) + "\n")
+	}
+	if err := os.WriteFile(file, []byte(b.String()), 0600); err != nil {
+		t.Fatal(err)
+	}
+	e := new(Editor)
+	if err := e.LoadHistory(file); err != nil {
+		t.Fatal(err)
+	}
+	if len(e.history) != MaxHistory {
+		t.Fatalf("loaded %d entries; want %d", len(e.history), MaxHistory)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "\n"); n != MaxHistory {
+		t.Errorf("trimmed file has %d lines; want %d", n, MaxHistory)
+	}
+}
+
+var historyMoveTests = []struct {
+	moves []int
+	want  string
+}{
+	{nil, "new"},
+	{[]int{-1}, "two"},
+	{[]int{-1, -1}, "one"},
+	{[]int{-1, -1, -1}, "one"},
+	{[]int{-1, -1, 1}, "two"},
+	{[]int{-1, 1}, "new"},
+	{[]int{1}, "new"},
+}
+
+func TestHistoryMove(t *testing.T) {
+	for _, test := range historyMoveTests {
+		e := &Editor{history: []string{"one", "two"}, histPos: 2, buf: []rune("new"), pos: 3}
+		for _, dir := range test.moves {
+			e.historyMove(dir)
+		}
+		if got := string(e.buf); got != test.want {
+			t.Errorf("moves %v: got %q; want %q", test.moves, got, test.want)
+		}
+		if e.pos != len(e.buf) {
+			t.Errorf("moves %v: cursor at %d; want %d", test.moves, e.pos, len(e.buf))
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package edit
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// MaxHistory is the number of history entries kept.
+const MaxHistory = 1000
+
+// In the history file each entry is one line. Newlines within an entry,
+// as in a multi-line op definition, are written as \n and backslashes as \\.
+var (
+	historyEscaper   = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	historyUnescaper = strings.NewReplacer(`\\`, `\`, `\n`, "\n")
+)
+
+// LoadHistory reads the history from the named file, which need not exist,
+// and arranges for later entries to be appended to it.
+func (e *Editor) LoadHistory(file string) error {
+	e.histFile = file
+	fd, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+	scanner := bufio.NewScanner(fd)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		e.history = append(e.history, historyUnescaper.Replace(scanner.Text()))
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(e.history) > MaxHistory {
+		// Trim the file so it does not grow without bound.
+		e.history = e.history[len(e.history)-MaxHistory:]
+		return e.writeHistory()
+	}
+	return nil
+}
+
+// AddHistory adds the entry to the history, unless it is empty or
+// repeats the previous entry. If there is a history file, the entry
+// is appended to it.
+func (e *Editor) AddHistory(entry string) {
+	if strings.TrimSpace(entry) == "" {
+		return
+	}
+	if n := len(e.history); n > 0 && e.history[n-1] == entry {
+		return
+	}
+	e.history = append(e.history, entry)
+	if len(e.history) > MaxHistory {
+		e.history = e.history[1:]
+	}
+	if e.histFile == "" {
+		return
+	}
+	fd, err := os.OpenFile(e.histFile, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return
+	}
+	fd.WriteString(historyEscaper.Replace(entry) + "\n")
+	fd.Close()
+}
+
+// writeHistory rewrites the history file with the current history.
+func (e *Editor) writeHistory() error {
+	var b strings.Builder
+	for _, entry := range e.history {
+		b.WriteString(historyEscaper.Replace(entry))
+		b.WriteByte('\n')
+	}
+	return os.WriteFile(e.histFile, []byte(b.String()), 0600)
+}
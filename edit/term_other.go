@@ -0,0 +1,31 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package edit
+
+import (
+	"errors"
+	"os"
+)
+
+// Line editing is not supported here; ivy reads standard input directly.
+
+type termState struct{}
+
+func isTerminal(fd *os.File) bool {
+	return false
+}
+
+func makeRaw(fd *os.File) (*termState, error) {
+	return nil, errors.New("line editing not supported")
+}
+
+func restore(fd *os.File, state *termState) {}
+
+func termWidth(fd *os.File) int {
+	return 0
+}
@@ -0,0 +1,64 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+package edit
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+func ioctl(fd *os.File, req uintptr, arg unsafe.Pointer) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd.Fd(), req, uintptr(arg))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+func isTerminal(fd *os.File) bool {
+	var t syscall.Termios
+	return ioctl(fd, ioctlGetTermios, unsafe.Pointer(&t)) == nil
+}
+
+// makeRaw puts the terminal into raw mode, much as cfmakeraw does,
+// and returns the previous state.
+func makeRaw(fd *os.File) (*syscall.Termios, error) {
+	var old syscall.Termios
+	if err := ioctl(fd, ioctlGetTermios, unsafe.Pointer(&old)); err != nil {
+		return nil, err
+	}
+	t := old
+	t.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP | syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	t.Oflag &^= syscall.OPOST
+	t.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	t.Cflag &^= syscall.CSIZE | syscall.PARENB
+	t.Cflag |= syscall.CS8
+	t.Cc[syscall.VMIN] = 1
+	t.Cc[syscall.VTIME] = 0
+	if err := ioctl(fd, ioctlSetTermios, unsafe.Pointer(&t)); err != nil {
+		return nil, err
+	}
+	return &old, nil
+}
+
+// restore returns the terminal to the state saved by makeRaw.
+func restore(fd *os.File, state *syscall.Termios) {
+	ioctl(fd, ioctlSetTermios, unsafe.Pointer(state))
+}
+
+// termWidth returns the width of the terminal, or 0 if it is unknown.
+func termWidth(fd *os.File) int {
+	var ws struct {
+		row, col, xpixel, ypixel uint16
+	}
+	if ioctl(fd, syscall.TIOCGWINSZ, unsafe.Pointer(&ws)) != nil {
+		return 0
+	}
+	return int(ws.col)
+}
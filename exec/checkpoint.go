@@ -0,0 +1,287 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package exec
+
+import (
+	"fmt"
+	"sort"
+
+	"robpike.io/ivy/config"
+	"robpike.io/ivy/value"
+)
+
+// A Checkpoint records the state of the workspace: the variables, the ops
+// and the configuration. Taking one is cheap: the tables are copied but
+// the values and functions in them are shared. Functions are never
+// modified, and values are modified only by indexed assignment, which
+// first copies any vector or matrix a checkpoint holds; see Writable.
+type Checkpoint struct {
+	globals  Symtab
+	unaryFn  map[string]*Function
+	binaryFn map[string]*Function
+	defs     []OpDef
+	config   config.Config
+	arrays   map[*value.Value]bool // The backing arrays of the vectors and matrices in globals.
+}
+
+// Checkpoint returns a checkpoint of the current state of the workspace.
+// The values it holds are protected from modification only once it is
+// stored in Checkpoints or used for undo.
+func (c *Context) Checkpoint() *Checkpoint {
+	cp := &Checkpoint{
+		globals:  make(Symtab, len(c.Globals)),
+		unaryFn:  make(map[string]*Function, len(c.UnaryFn)),
+		binaryFn: make(map[string]*Function, len(c.BinaryFn)),
+		defs:     append([]OpDef(nil), c.Defs...),
+		config:   *c.config,
+		arrays:   make(map[*value.Value]bool),
+	}
+	for name, val := range c.Globals {
+		cp.globals[name] = val
+		if p := array(val); p != nil {
+			cp.arrays[p] = true
+		}
+	}
+	for name, fn := range c.UnaryFn {
+		cp.unaryFn[name] = fn
+	}
+	for name, fn := range c.BinaryFn {
+		cp.binaryFn[name] = fn
+	}
+	return cp
+}
+
+// Rollback returns the workspace to the state recorded in the checkpoint.
+// The output streams, the sandbox setting and the log are not changed.
+func (c *Context) Rollback(cp *Checkpoint) {
+	c.Globals = make(Symtab, len(cp.globals))
+	for name, val := range cp.globals {
+		c.Globals[name] = val
+	}
+	c.UnaryFn = make(map[string]*Function, len(cp.unaryFn))
+	for name, fn := range cp.unaryFn {
+		c.UnaryFn[name] = fn
+	}
+	c.BinaryFn = make(map[string]*Function, len(cp.binaryFn))
+	for name, fn := range cp.binaryFn {
+		c.BinaryFn[name] = fn
+	}
+	c.Defs = append([]OpDef(nil), cp.defs...)
+	conf := cp.config
+	conf.SetOutput(c.config.Output())
+	conf.SetErrOutput(c.config.ErrOutput())
+	conf.SetSandbox(c.config.Sandbox())
+	conf.SetLog(c.config.Log())
+	*c.config = conf
+}
+
+// SaveUndo records the state of the workspace so a later call
+// to Undo can return to it. It is called before each line of input
+// is executed.
+func (c *Context) SaveUndo() {
+	c.undo = c.next
+	c.next = c.Checkpoint()
+}
+
+// Undo returns the workspace to its state before the previous line of
+// input, and reports whether there was such a state. Since the line
+// that calls Undo is itself recorded, a second Undo reverses the first.
+func (c *Context) Undo() bool {
+	if c.undo == nil {
+		return false
+	}
+	c.Rollback(c.undo)
+	return true
+}
+
+// array returns a pointer to the first element of the
+// backing array of v, if it is a non-empty vector or matrix.
+func array(v value.Value) *value.Value {
+	var data value.Vector
+	switch v := v.(type) {
+	case value.Vector:
+		data = v
+	case *value.Matrix:
+		data = v.Data()
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return &data[0]
+}
+
+// Writable returns v, or a copy of it if v is a vector or matrix held by
+// a checkpoint or a vector stored unboxed, and reports whether it made a
+// copy. Indexed assignment must write only to a writable value.
+func (c *Context) Writable(v value.Value) (value.Value, bool) {
+	switch v.(type) {
+	case value.Int64Vector, value.Float64Vector:
+		return value.Boxed(v), true
+	}
+	p := array(v)
+	if p == nil {
+		return v, false
+	}
+	held := c.undo != nil && c.undo.arrays[p] || c.next != nil && c.next.arrays[p]
+	for _, cp := range c.Checkpoints {
+		held = held || cp.arrays[p]
+	}
+	if !held {
+		return v, false
+	}
+	switch v := v.(type) {
+	case value.Vector:
+		return v.Copy(), true
+	case *value.Matrix:
+		return v.Copy(), true
+	}
+	panic("not reached")
+}
+
+// Diff returns a description, one line per difference, of the changes
+// to the workspace from the checkpoint to the checkpoint to.
+func (cp *Checkpoint) Diff(to *Checkpoint) []string {
+	var diffs []string
+	for _, name := range names(cp.globals, to.globals) {
+		old, new := cp.globals[name], to.globals[name]
+		if value.IsConstant(name) || name == "_" {
+			continue
+		}
+		if d := change(old != nil, new != nil, old == nil || new == nil || !sameValue(old, new)); d != "" {
+			diffs = append(diffs, name+": "+d)
+		}
+	}
+	for _, name := range names(cp.unaryFn, to.unaryFn) {
+		old, new := cp.unaryFn[name], to.unaryFn[name]
+		if d := change(old != nil, new != nil, old != new && (old == nil || new == nil || old.String() != new.String())); d != "" {
+			diffs = append(diffs, fmt.Sprintf("op %s _: %s", name, d))
+		}
+	}
+	for _, name := range names(cp.binaryFn, to.binaryFn) {
+		old, new := cp.binaryFn[name], to.binaryFn[name]
+		if d := change(old != nil, new != nil, old != new && (old == nil || new == nil || old.String() != new.String())); d != "" {
+			diffs = append(diffs, fmt.Sprintf("op _ %s _: %s", name, d))
+		}
+	}
+	oldSettings, newSettings := settings(&cp.config), settings(&to.config)
+	for i, old := range oldSettings {
+		if new := newSettings[i]; old.value != new.value {
+			diffs = append(diffs, fmt.Sprintf(")%s: %s -> %s", old.name, old.value, new.value))
+		}
+	}
+	return diffs
+}
+
+// change describes the change to an item that was present in the old
+// state if old is set and in the new if new is set, and differs if
+// differ is set. It returns the empty string if nothing changed.
+func change(old, new, differ bool) string {
+	switch {
+	case !old:
+		return "added"
+	case !new:
+		return "deleted"
+	case differ:
+		return "changed"
+	}
+	return ""
+}
+
+// names returns the sorted union of the keys of the maps, which must be
+// of the same type: Symtab or map[string]*Function.
+func names(a, b interface{}) []string {
+	seen := make(map[string]bool)
+	switch a := a.(type) {
+	case Symtab:
+		for name := range a {
+			seen[name] = true
+		}
+		for name := range b.(Symtab) {
+			seen[name] = true
+		}
+	case map[string]*Function:
+		for name := range a {
+			seen[name] = true
+		}
+		for name := range b.(map[string]*Function) {
+			seen[name] = true
+		}
+	}
+	list := make([]string, 0, len(seen))
+	for name := range seen {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// sameValue reports whether the values are identical, in type as well as value.
+func sameValue(a, b value.Value) bool {
+	if p := array(a); p != nil && p == array(b) {
+		return true
+	}
+	a, b = value.Boxed(a), value.Boxed(b)
+	switch a := a.(type) {
+	case value.Vector:
+		b, ok := b.(value.Vector)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !sameValue(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case *value.Matrix:
+		b, ok := b.(*value.Matrix)
+		if !ok || fmt.Sprint(a.Shape()) != fmt.Sprint(b.Shape()) {
+			return false
+		}
+		return sameValue(a.Data(), b.Data())
+	case value.BigFloat:
+		// A float has no program text.
+		b, ok := b.(value.BigFloat)
+		return ok && a.Prec() == b.Prec() && a.Cmp(b.Float) == 0
+	}
+	return fmt.Sprintf("%T", a) == fmt.Sprintf("%T", b) && a.ProgString() == b.ProgString()
+}
+
+// setting is the name and printed value of a configuration setting.
+type setting struct {
+	name, value string
+}
+
+// settings returns the configuration settings that a checkpoint
+// records, in the form the special commands print them.
+func settings(conf *config.Config) []setting {
+	ibase, obase := conf.Base()
+	modulus := "0"
+	if m := conf.Modulus(); m != nil {
+		modulus = m.String()
+	}
+	return []setting{
+		{"format", fmt.Sprintf("%q", conf.Format())},
+		{"glyphs", fmt.Sprint(truth(conf.Glyphs()))},
+		{"ibase", fmt.Sprint(ibase)},
+		{"machine", fmt.Sprint(truth(conf.Machine()))},
+		{"maxbits", fmt.Sprint(conf.MaxBits())},
+		{"maxdigits", fmt.Sprint(conf.MaxDigits())},
+		{"maxstack", fmt.Sprint(conf.MaxStack())},
+		{"modulus", modulus},
+		{"obase", fmt.Sprint(obase)},
+		{"origin", fmt.Sprint(conf.Origin())},
+		{"prec", fmt.Sprint(conf.FloatPrec())},
+		{"prompt", fmt.Sprintf("%q", conf.Prompt())},
+		{"seed", fmt.Sprint(conf.RandomSeed())},
+	}
+}
+
+func truth(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
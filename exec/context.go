@@ -34,6 +34,14 @@ type Context struct {
 	// Defs is a list of defined ops, in time order.  It is used when saving the
 	// Context to a file.
 	Defs []OpDef
+	// Libraries maps the absolute path of each library loaded by )lib
+	// to the name it was loaded under, so none is loaded twice.
+	Libraries map[string]string
+	// Checkpoints maps the names given to )checkpoint to the saved states.
+	Checkpoints map[string]*Checkpoint
+	// undo holds the state before the previous line of input, and next
+	// the state before the current one. See SaveUndo.
+	undo, next *Checkpoint
 	// Names of variables declared in the currently-being-parsed function.
 	variables []string
 }
@@ -42,10 +50,12 @@ type Context struct {
 // plus the execution configuration.
 func NewContext(conf *config.Config) value.Context {
 	c := &Context{
-		config:   conf,
-		Globals:  make(Symtab),
-		UnaryFn:  make(map[string]*Function),
-		BinaryFn: make(map[string]*Function),
+		config:      conf,
+		Globals:     make(Symtab),
+		UnaryFn:     make(map[string]*Function),
+		BinaryFn:    make(map[string]*Function),
+		Libraries:   make(map[string]string),
+		Checkpoints: make(map[string]*Checkpoint),
 	}
 	c.SetConstants()
 	return c
@@ -56,16 +66,23 @@ func (c *Context) Config() *config.Config {
 }
 
 // SetConstants re-assigns the fundamental constant values using the current
-// setting of floating-point precision.
+// setting of floating-point precision. Other constants, such as euler, are
+// computed when used; see Global.
 func (c *Context) SetConstants() {
-	e, pi := value.Consts(c)
-	c.AssignGlobal("e", e)
-	c.AssignGlobal("pi", pi)
+	for name, val := range value.Consts(c) {
+		c.AssignGlobal(name, val)
+	}
 }
 
 // Global returns the value of a global symbol, or nil if the symbol is not defined globally.
+// A predefined constant such as euler that the user has not assigned is computed
+// to the current precision; it is not stored, so Globals holds only user variables.
 func (c *Context) Global(name string) value.Value {
-	return c.Globals[name]
+	val := c.Globals[name]
+	if val == nil && value.IsPredefined(name) {
+		val = value.Constant(c, name)
+	}
+	return val
 }
 
 // Local returns the value of the local variable with index i.
@@ -204,6 +221,53 @@ func (c *Context) Define(fn *Function) {
 	c.Defs = append(c.Defs, OpDef{fn.Name, fn.IsBinary})
 }
 
+// EraseVar removes the global variable with the given name.
+// It reports whether the variable was defined.
+func (c *Context) EraseVar(name string) bool {
+	if value.IsConstant(name) { // Cannot remove these.
+		value.Errorf("cannot erase %q", name)
+	}
+	if c.Globals[name] == nil {
+		return false
+	}
+	delete(c.Globals, name)
+	return true
+}
+
+// EraseOp removes the user-defined unary or binary op with the given name.
+// It reports whether the op was defined.
+func (c *Context) EraseOp(name string, isBinary bool) bool {
+	fns := c.UnaryFn
+	if isBinary {
+		fns = c.BinaryFn
+	}
+	if fns[name] == nil {
+		return false
+	}
+	delete(fns, name)
+	for i, def := range c.Defs {
+		if def.Name == name && def.IsBinary == isBinary {
+			c.Defs = append(c.Defs[:i], c.Defs[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// Clear returns the context to its initial state, with no ops or
+// variables other than the constants. The configuration is unchanged.
+func (c *Context) Clear() {
+	c.frameSizes = nil
+	c.stack = nil
+	c.Globals = make(Symtab)
+	c.UnaryFn = make(map[string]*Function)
+	c.BinaryFn = make(map[string]*Function)
+	c.Defs = nil
+	c.Libraries = make(map[string]string)
+	c.variables = nil
+	c.SetConstants()
+}
+
 // noVar guarantees that there is no global variable with that name,
 // preventing an op from being defined with the same name as a variable,
 // which could cause problems. A variable with value zero is considered to
@@ -211,7 +275,7 @@ func (c *Context) Define(fn *Function) {
 // variable is removed from the global symbol table.
 // noVar also prevents defining builtin variables as ops.
 func (c *Context) noVar(name string) {
-	if name == "_" || name == "pi" || name == "e" { // Cannot redefine these.
+	if name == "_" || value.IsConstant(name) { // Cannot redefine these.
 		value.Errorf(`cannot define op with name %q`, name)
 	}
 	sym := c.Globals[name]
@@ -222,13 +286,13 @@ func (c *Context) noVar(name string) {
 		delete(c.Globals, name)
 		return
 	}
-	value.Errorf("cannot define op %s; it is a variable (%[1]s=0 to clear)", name)
+	value.Errorf("cannot define op %s; it is a variable ()erase %[1]s to clear)", name)
 }
 
 // noOp is the dual of noVar. It also checks for assignment to builtins.
 // It just errors out if there is a conflict.
 func (c *Context) noOp(name string) {
-	if name == "pi" || name == "e" { // Cannot redefine these.
+	if value.IsConstant(name) { // Cannot redefine these.
 		value.Errorf("cannot reassign %q", name)
 	}
 	if c.UnaryFn[name] == nil && c.BinaryFn[name] == nil {
@@ -0,0 +1,181 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"robpike.io/ivy/config"
+	"robpike.io/ivy/edit"
+	"robpike.io/ivy/exec"
+	"robpike.io/ivy/parse"
+	"robpike.io/ivy/value"
+)
+
+// continuationPrompt is the prompt for the body of a multi-line op definition.
+const continuationPrompt = "    "
+
+// stdinReader returns the reader for interactive input. If standard input
+// and output are a terminal, input is read through a line editor; otherwise
+// standard input is read directly.
+func stdinReader(context value.Context) io.ByteReader {
+	ed, err := edit.New(os.Stdin, os.Stdout)
+	if err != nil {
+		return bufio.NewReader(os.Stdin)
+	}
+	if file := historyFile(); file != "" {
+		if err := ed.LoadHistory(file); err != nil {
+			fmt.Fprintf(os.Stderr, "ivy: history: %s\n", err)
+		}
+	}
+	ed.Complete = completer(context)
+	return &lineReader{
+		ed:   ed,
+		conf: context.Config(),
+	}
+}
+
+// historyFile returns the name of the file holding the history of
+// interactive input: $IVYHISTORY if set, otherwise ~/.ivy_history.
+func historyFile() string {
+	if file := os.Getenv("IVYHISTORY"); file != "" {
+		return file
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".ivy_history")
+}
+
+// lineReader is an io.ByteReader that delivers input from the line editor.
+type lineReader struct {
+	ed   *edit.Editor
+	conf *config.Config
+	line []byte   // Unread input.
+	defn []string // Lines of the op definition being typed, if any.
+}
+
+func (r *lineReader) ReadByte() (byte, error) {
+	for len(r.line) == 0 {
+		prompt := r.conf.Prompt()
+		if r.defn != nil {
+			prompt = continuationPrompt
+		}
+		line, err := r.ed.ReadLine(prompt)
+		if err == edit.ErrInterrupt {
+			// Discard the line.
+			line, err = "", nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		r.record(line)
+		r.line = []byte(line + "\n")
+	}
+	c := r.line[0]
+	r.line = r.line[1:]
+	return c, nil
+}
+
+// record adds the line to the history. The lines of a multi-line op
+// definition are saved as a single entry when the definition is complete,
+// so it can be recalled and edited as a unit.
+func (r *lineReader) record(line string) {
+	switch {
+	case r.defn != nil:
+		r.defn = append(r.defn, line)
+		if strings.TrimSpace(line) == "" {
+			r.ed.AddHistory(strings.Join(r.defn, "\n"))
+			r.defn = nil
+		}
+	case !strings.Contains(line, "\n") && startsDefinition(line):
+		r.defn = []string{line}
+	default:
+		r.ed.AddHistory(line)
+	}
+}
+
+// startsDefinition reports whether the line begins a multi-line op
+// definition, one whose body follows on later lines.
+func startsDefinition(line string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, "op ") && strings.HasSuffix(line, "=")
+}
+
+func isIdentifierRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// completer returns the completion function for the line editor. After a
+// right parenthesis at the start of the line it completes special commands;
+// elsewhere it completes the names of operators and variables.
+func completer(context value.Context) func(line []rune, pos int) (int, []string) {
+	return func(line []rune, pos int) (int, []string) {
+		start := pos
+		for start > 0 && isIdentifierRune(line[start-1]) {
+			start--
+		}
+		word := string(line[start:pos])
+		var names []string
+		if strings.TrimSpace(string(line[:start])) == ")" {
+			names = parse.SpecialCommands()
+		} else {
+			if word == "" || unicode.IsDigit(line[start]) {
+				return start, nil
+			}
+			names = identifiers(context)
+		}
+		var candidates []string
+		for _, name := range names {
+			if strings.HasPrefix(name, word) {
+				candidates = append(candidates, name)
+			}
+		}
+		return start, candidates
+	}
+}
+
+// identifiers returns, sorted and without duplicates, the names of the
+// predefined operators and the user's ops and variables.
+func identifiers(context value.Context) []string {
+	seen := make(map[string]bool)
+	add := func(name string) {
+		r := []rune(name)
+		if len(r) > 0 && (r[0] == '_' || unicode.IsLetter(r[0])) {
+			seen[name] = true
+		}
+	}
+	for name := range value.UnaryOps {
+		add(name)
+	}
+	for name := range value.BinaryOps {
+		add(name)
+	}
+	if c, ok := context.(*exec.Context); ok {
+		for name := range c.UnaryFn {
+			add(name)
+		}
+		for name := range c.BinaryFn {
+			add(name)
+		}
+		for name := range c.Globals {
+			add(name)
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"runtime/pprof"
 	"strings"
 
@@ -24,14 +25,24 @@ import (
 var (
 	execute         = flag.String("e", "", "execute `argument` and quit")
 	executeContinue = flag.String("i", "", "execute `argument` and continue")
+	begin           = flag.String("begin", "", "in stream mode, execute `argument` before reading input")
+	each            = flag.String("each", "", "stream mode: execute `argument` for each line of input, which is in x")
+	end             = flag.String("end", "", "in stream mode, execute `argument` after reading input")
+	all             = flag.Bool("all", false, "set x to all of standard input, one row per line, before -e")
+	check           = flag.Bool("check", false, "run the examples in the files and report output that differs from that expected")
 	file            = flag.String("f", "", "execute `file` before input")
 	format          = flag.String("format", "", "use `fmt` as format for printing numbers; empty sets default format")
 	gformat         = flag.Bool("g", false, `shorthand for -format="%.12g"`)
+	jsonFlag        = flag.Bool("json", false, "print each result as a line of JSON")
+	logFile         = flag.String("log", "", "record the interactive session, with timestamps, in `file`")
 	maxbits         = flag.Uint("maxbits", 1e9, "maximum size of an integer, in bits; 0 means no limit")
 	maxdigits       = flag.Uint("maxdigits", 1e4, "above this many `digits`, integers print as floating point; 0 disables")
 	maxstack        = flag.Uint("stack", 100000, "maximum call stack `depth` allowed")
+	norc            = flag.Bool("norc", false, "do not run the startup file, $IVYRC or ~/.ivyrc")
 	origin          = flag.Int("origin", 1, "set index origin to `n` (must be 0 or 1)")
 	prompt          = flag.String("prompt", "", "command `prompt`")
+	replayFile      = flag.String("replay", "", "execute the input recorded in the log `file` and report output that differs")
+	sandbox         = flag.Bool("sandbox", false, "forbid ivy programs to read or write files")
 	debugFlag       = flag.String("debug", "", "comma-separated `names` of debug settings to enable")
 )
 
@@ -48,21 +59,50 @@ func main() {
 	pprof.StartCPUProfile(f)
 	defer pprof.StopCPUProfile()
 
+	// The exit operator unwinds evaluation with a value.Exit.
+	defer func() {
+		if err := recover(); err != nil {
+			status, ok := err.(value.Exit)
+			if !ok {
+				panic(err)
+			}
+			// Deferred calls do not run on os.Exit, so flush the log here.
+			if log := conf.Log(); log != nil {
+				if err := log.Close(); err != nil {
+					fmt.Fprintf(os.Stderr, "ivy: log: %s\n", err)
+				}
+			}
+			os.Exit(int(status))
+		}
+	}()
+
 	if *origin != 0 && *origin != 1 {
 		fmt.Fprintf(os.Stderr, "ivy: illegal origin value %d\n", *origin)
 		os.Exit(2)
 	}
 
+	if *all && *execute == "" {
+		fmt.Fprintf(os.Stderr, "ivy: -all requires -e\n")
+		os.Exit(2)
+	}
+
+	if *all && *each != "" {
+		fmt.Fprintf(os.Stderr, "ivy: -all and -each cannot be used together\n")
+		os.Exit(2)
+	}
+
 	if *gformat {
 		*format = "%.12g"
 	}
 
 	conf.SetFormat(*format)
+	conf.SetJSON(*jsonFlag)
 	conf.SetMaxBits(*maxbits)
 	conf.SetMaxDigits(*maxdigits)
 	conf.SetMaxStack(*maxstack)
 	conf.SetOrigin(*origin)
 	conf.SetPrompt(*prompt)
+	conf.SetSandbox(*sandbox)
 
 	if len(*debugFlag) > 0 {
 		for _, debug := range strings.Split(*debugFlag, ",") {
@@ -73,8 +113,28 @@ func main() {
 		}
 	}
 
+	if *check {
+		ok := true
+		for _, file := range flag.Args() {
+			ok = checkFile(&conf, file, os.Stdout) && ok
+		}
+		if !ok {
+			os.Exit(1)
+		}
+		return
+	}
+
 	context = exec.NewContext(&conf)
 
+	files, args, hasArgs := splitArgs()
+	if hasArgs {
+		context.AssignGlobal("args", value.NewCharMatrix(args))
+	}
+
+	if !*norc {
+		runRC(context)
+	}
+
 	if *file != "" {
 		if !runFile(context, *file) {
 			os.Exit(1)
@@ -87,6 +147,24 @@ func main() {
 		}
 	}
 
+	if *all {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ivy: %s\n", err)
+			os.Exit(1)
+		}
+		context.AssignGlobal("x", allValue(&conf, string(data)))
+	}
+
+	if *each != "" || *begin != "" || *end != "" {
+		if !runStream(context, os.Stdin, *begin, *each, *end) {
+			os.Exit(1)
+		}
+		if *execute == "" {
+			return
+		}
+	}
+
 	if *execute != "" {
 		if !runString(context, *execute) {
 			os.Exit(1)
@@ -94,19 +172,75 @@ func main() {
 		return
 	}
 
-	if flag.NArg() > 0 {
-		for i := 0; i < flag.NArg(); i++ {
-			if !runFile(context, flag.Arg(i)) {
+	if len(files) > 0 {
+		for _, file := range files {
+			if !runFile(context, file) {
 				os.Exit(1)
 			}
 		}
 		return
 	}
 
-	scanner := scan.New(context, "<stdin>", bufio.NewReader(os.Stdin))
+	if *replayFile != "" {
+		if !replay(context, *replayFile, os.Stdout) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *logFile != "" {
+		log, err := config.CreateLog(*logFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ivy: %s\n", err)
+			os.Exit(1)
+		}
+		conf.SetLog(log)
+	}
+
+	scanner := scan.New(context, "<stdin>", &logReader{r: stdinReader(context), conf: &conf})
 	parser := parse.NewParser("<stdin>", scanner, context)
 	for !run.Run(parser, context, true) {
 	}
+	if log := conf.Log(); log != nil {
+		if err := log.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "ivy: log: %s\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// splitArgs separates the files named on the command line from the
+// arguments for the program, which follow "--" or, if the first file
+// begins with "#!", that file. It reports whether there are program
+// arguments, even if none follow "--".
+func splitArgs() (files, args []string, hasArgs bool) {
+	files = flag.Args()
+	// The flag package consumes a "--" that ends the flags.
+	if n := len(os.Args) - len(files); n > 1 && os.Args[n-1] == "--" {
+		return nil, files, true
+	}
+	for i, arg := range files {
+		if arg == "--" {
+			return files[:i], files[i+1:], true
+		}
+	}
+	if len(files) > 0 && isScript(files[0]) {
+		return files[:1], files[1:], true
+	}
+	return files, nil, false
+}
+
+// isScript reports whether the file begins with "#!", so it can be
+// run as an executable that is passed the rest of the arguments.
+func isScript(file string) bool {
+	fd, err := os.Open(value.FindFile(file))
+	if err != nil {
+		return false
+	}
+	defer fd.Close()
+	buf := make([]byte, 2)
+	_, err = io.ReadFull(fd, buf)
+	return err == nil && string(buf) == "#!"
 }
 
 // runFile executes the contents of the file as an ivy program.
@@ -119,7 +253,7 @@ func runFile(context value.Context, file string) bool {
 		fd = os.Stdin
 	} else {
 		interactive = false
-		fd, err = os.Open(file)
+		fd, err = os.Open(value.FindFile(file))
 	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "ivy: %s\n", err)
@@ -130,6 +264,31 @@ func runFile(context value.Context, file string) bool {
 	return run.Run(parser, context, interactive)
 }
 
+// runRC executes the startup file named by $IVYRC, or else ~/.ivyrc if it exists.
+// An error in the file is reported but does not stop ivy.
+func runRC(context value.Context) {
+	file := os.Getenv("IVYRC")
+	if file == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return
+		}
+		file = filepath.Join(home, ".ivyrc")
+		if _, err := os.Stat(file); err != nil {
+			return
+		}
+	}
+	fd, err := os.Open(value.FindFile(file))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ivy: %s\n", err)
+		return
+	}
+	defer fd.Close()
+	scanner := scan.New(context, file, bufio.NewReader(fd))
+	parser := parse.NewParser(file, scanner, context)
+	run.Run(parser, context, false)
+}
+
 // runString executes the string, typically a command-line argument, as an ivy program.
 func runString(context value.Context, str string) bool {
 	scanner := scan.New(context, "<args>", strings.NewReader(str))
@@ -138,7 +297,7 @@ func runString(context value.Context, str string) bool {
 }
 
 func usage() {
-	fmt.Fprintf(os.Stderr, "usage: ivy [options] [file ...]\n")
+	fmt.Fprintf(os.Stderr, "usage: ivy [options] [file ...] [-- arg ...]\n")
 	fmt.Fprintf(os.Stderr, "Flags:\n")
 	flag.PrintDefaults()
 	os.Exit(2)
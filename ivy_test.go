@@ -5,17 +5,23 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
 	"io/ioutil"
 	"os"
+	osexec "os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"robpike.io/ivy/config"
 	"robpike.io/ivy/exec"
+	"robpike.io/ivy/parse"
 	"robpike.io/ivy/run"
+	"robpike.io/ivy/scan"
 	"robpike.io/ivy/value"
 )
 
@@ -29,6 +35,16 @@ func init() {
 
 // Note: These tests share some infrastructure and cannot run in parallel.
 
+// TestMain runs ivy itself, instead of the tests, if $IVY_TEST_MAIN is
+// set, so tests can run the command.
+func TestMain(m *testing.M) {
+	if os.Getenv("IVY_TEST_MAIN") != "" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
 func TestAll(t *testing.T) {
 	var err error
 	check := func() {
@@ -49,31 +65,17 @@ func TestAll(t *testing.T) {
 		path := filepath.Join("testdata", name)
 		data, err = ioutil.ReadFile(path)
 		check()
-		text := string(data)
-		lines := strings.Split(text, "\n")
-		// Will have a trailing empty string.
-		if len(lines) > 0 && lines[len(lines)-1] == "" {
-			lines = lines[:len(lines)-1]
-		}
-		lineNum := 1
 		errCount := 0
-		for len(lines) > 0 {
-			// Assemble the input to one example.
-			input, output, length := getText(t, path, lineNum, lines)
-			if input == nil {
-				break
-			}
+		for _, block := range checkBlocks(string(data)) {
 			if verbose {
-				fmt.Printf("%s:%d: %s\n", path, lineNum, input)
+				fmt.Printf("%s:%d: %s\n", path, block.line, block.input)
 			}
-			if !runTest(t, path, lineNum, input, output) {
+			if !runTest(t, path, block.line, block.input, block.output) {
 				errCount++
 				if errCount > 3 {
 					t.Fatal("too many errors")
 				}
 			}
-			lines = lines[length:]
-			lineNum += length
 		}
 	}
 }
@@ -109,69 +111,355 @@ func runTest(t *testing.T, name string, lineNum int, input, output []string) boo
 	return true
 }
 
-func equal(a, b []string) bool {
-	// Split leaves an empty trailing line.
-	if len(a) > 0 && a[len(a)-1] == "" {
-		a = a[:len(a)-1]
+var streamTests = []struct {
+	input            string
+	begin, each, end string
+	all              string
+	output           string
+}{
+	{"1\n2\n3\n", "s=0", "s=s+x", "s", "", "6\n"},
+	{"1 2\nhi there\n", "", "nr; rho x", "", "", "1 2\n2 8\n"},
+	{"1\n2\n3\n", "", "", "", "+/x", "6\n"},
+	{"1 2\n3 4\n", "", "", "", "rho x", "2 2\n"},
+	{"1 2\n3\n", "", "", "", "rho x", "2 3\n"},
+	{"1/2/3\n", "", "rho x", "", "", "5\n"},
+	{"1/2 1/2/3\n4\n", "", "", "", "rho x", "2 9\n"},
+}
+
+func TestStream(t *testing.T) {
+	for _, test := range streamTests {
+		reset()
+		out := new(bytes.Buffer)
+		testConf.SetOutput(out)
+		testConf.SetErrOutput(out)
+		context := exec.NewContext(&testConf)
+		ok := true
+		if test.all != "" {
+			context.AssignGlobal("x", allValue(&testConf, test.input))
+			ok = runString(context, test.all)
+		} else {
+			ok = runStream(context, strings.NewReader(test.input), test.begin, test.each, test.end)
+		}
+		if !ok || out.String() != test.output {
+			t.Errorf("%q: got %q; want %q", test.input, out, test.output)
+		}
 	}
-	if len(a) != len(b) {
-		return false
+}
+
+func TestSnapshot(t *testing.T) {
+	reset()
+	file := filepath.Join(t.TempDir(), "test.snap")
+	context := exec.NewContext(&testConf)
+	setup := `
+		)prec 100
+		)origin 0
+		op double x = 2*x
+		op a twice b = a * double b
+		x = sqrt 2
+		y = 1j1/3
+		z = 2 3 rho 1/3 'a' (2**100) (float 1/10) 3 4
+		w = 1 interval 2
+		)obase 16
+	`
+	check := `
+		)prec
+		)origin
+		)obase
+		x
+		x == sqrt 2
+		y
+		z
+		w
+		3 twice 4
+	`
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	run.Ivy(context, setup, stdout, stderr)
+	run.Ivy(context, check, stdout, stderr)
+	want := stdout.String()
+	run.Ivy(context, fmt.Sprintf(")snapshot %q\n)clear\n)obase 0\n)prec 256\n", file), stdout, stderr)
+	stdout.Reset()
+	run.Ivy(context, fmt.Sprintf(")restore %q\n", file), stdout, stderr)
+	run.Ivy(context, check, stdout, stderr)
+	if stderr.Len() != 0 {
+		t.Fatal(stderr)
+	}
+	if got := stdout.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestLog(t *testing.T) {
+	reset()
+	file := filepath.Join(t.TempDir(), "test.log")
+	context := exec.NewContext(&testConf)
+	out := new(bytes.Buffer)
+	testConf.SetOutput(out)
+	testConf.SetErrOutput(out)
+	input := fmt.Sprintf(")log %q\nx = 1 2 3\n+/x\n1/0\nop f a =\n  a*2\n\nf x\n)log off\n5\n", file)
+	reader := &logReader{r: bufio.NewReader(strings.NewReader(input)), conf: &testConf}
+	parser := parse.NewParser("<stdin>", scan.New(context, "<stdin>", reader), context)
+	for !run.Run(parser, context, false) {
 	}
-	for i, s := range a {
-		if strings.TrimSpace(s) != strings.TrimSpace(b[i]) {
-			return false
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		kind, text, ok := config.ParseLog(line)
+		if !ok {
+			t.Fatalf("bad record %q", line)
 		}
+		got = append(got, string(kind)+" "+text)
+	}
+	want := []string{
+		"< x = 1 2 3",
+		"< +/x",
+		"> 6",
+		"< 1/0",
+		"! zero denominator in rational",
+		"< op f a =",
+		"<   a*2",
+		"< ",
+		"< f x",
+		"> 2 4 6",
+		"< )log off",
+	}
+	if !equal(got, want) {
+		t.Fatalf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Replay the log, then a copy with a changed output.
+	reset()
+	diffs := new(bytes.Buffer)
+	if !replay(exec.NewContext(&testConf), file, diffs) {
+		t.Fatalf("replay failed:\n%s", diffs)
+	}
+	bad := strings.Replace(string(data), "> 6\n", "> 7\n", 1)
+	if err := ioutil.WriteFile(file, []byte(bad), 0666); err != nil {
+		t.Fatal(err)
+	}
+	reset()
+	if replay(exec.NewContext(&testConf), file, diffs) {
+		t.Fatal("replay succeeded with changed output")
+	}
+	if want := file + ":1:"; !strings.HasPrefix(diffs.String(), want) {
+		t.Errorf("replay reported:\n%s\nwant report starting %s", diffs, want)
+	}
+}
+
+// TestHighPrecisionLog checks that log is accurate and quick at high
+// precision, where the series it once used took minutes.
+func TestHighPrecisionLog(t *testing.T) {
+	reset()
+	input := `)prec 100000
+x = (log 6) - (log 2) + log 3
+(abs x) < 2**-99980
+(log 1000) == 3 * log 10
+`
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	start := time.Now()
+	run.Ivy(exec.NewContext(&testConf), input, stdout, stderr)
+	if elapsed := time.Since(start); elapsed > 20*time.Second {
+		t.Errorf("took %s; want under 20s", elapsed)
+	}
+	if stderr.Len() != 0 || stdout.String() != "1\n1\n" {
+		t.Errorf("got %q, errors %q; want %q", stdout, stderr, "1\n1\n")
 	}
-	return true
 }
 
-func getText(t *testing.T, fileName string, lineNum int, lines []string) (input, output []string, length int) {
-	// Skip blank and initial comment lines.
-	for _, line := range lines {
-		if len(line) > 0 && !strings.HasPrefix(line, "#") {
-			break
+// TestLibraryRetry checks that a library that fails to load is not
+// recorded as loaded, so a later )lib runs it again.
+func TestLibraryRetry(t *testing.T) {
+	reset()
+	input := `)lib "testdata/lib/needx"
+x = 2
+)lib "testdata/lib/needx"
+y
+)lib "testdata/lib/needx"
+`
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	run.Ivy(exec.NewContext(&testConf), input, stdout, stderr)
+	if !strings.Contains(stderr.String(), "undefined") {
+		t.Errorf("first load: got errors %q; want undefined variable", stderr)
+	}
+	if want := "needx loaded\n3\n"; stdout.String() != want {
+		t.Errorf("got %q; want %q", stdout, want)
+	}
+}
+
+// TestJSONCommand checks that ivy -json prints each result as exactly one
+// line, without the blank lines that separate results for a person.
+func TestJSONCommand(t *testing.T) {
+	cmd := osexec.Command(os.Args[0], "-json")
+	cmd.Env = append(os.Environ(), "IVY_TEST_MAIN=1", "IVYRC=", "HOME="+t.TempDir())
+	cmd.Stdin = strings.NewReader("1 2\nx = 3\n'ab'\n1/0\nx\n")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("%v: %s", err, stderr.Bytes())
+	}
+	if want := "[1,2]\n\"ab\"\n3\n"; string(out) != want {
+		t.Errorf("got %q; want %q", out, want)
+	}
+	if want := "zero denominator in rational\n"; stderr.String() != want {
+		t.Errorf("got error output %q; want %q", stderr.Bytes(), want)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	reset()
+	file := filepath.Join(t.TempDir(), "test.ivy")
+	text := `# A comment.
+
+x = 3
+x+1
+	4
+
+iota 3
+	1 2 4
+
+'a' 'b'
+	ab
+`
+	if err := ioutil.WriteFile(file, []byte(text), 0666); err != nil {
+		t.Fatal(err)
+	}
+	out := new(bytes.Buffer)
+	if checkFile(&testConf, file, out) {
+		t.Fatal("check succeeded with wrong output")
+	}
+	want := file + ":7:\n\tiota 3\ngot:\n\t1 2 3\nwant:\n\t1 2 4\n"
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestTests(t *testing.T) {
+	reset()
+	file := filepath.Join(t.TempDir(), "tests.ivy")
+	text := `
+op double x = 2*x
+op testDouble x = assert 6 == double 3
+op testWrong x = assert 7 == double 3
+op testDivide x = 1 / 0
+op a testBinary b = assert 0
+op check x = assert 0
+`
+	if err := ioutil.WriteFile(file, []byte(text), 0666); err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	run.Ivy(exec.NewContext(&testConf), fmt.Sprintf(")test %q\n", file), stdout, stderr)
+	// Times vary, so replace them.
+	got := regexp.MustCompile(`\t[0-9.]+[µm]?s.*`).ReplaceAllString(stdout.String(), "\tTIME")
+	want := "ok\ttestDouble\tTIME\n" +
+		"FAIL\ttestWrong\tTIME\n" +
+		"\tassert failed: 7 == double 3: left 7, right 6\n" +
+		"FAIL\ttestDivide\tTIME\n" +
+		"\tdivision by zero\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if want := "1 passed, 2 failed\n"; stderr.String() != want {
+		t.Errorf("got error %q; want %q", stderr, want)
+	}
+}
+
+var completeTests = []struct {
+	line  string
+	pos   int
+	start int
+	want  []string
+}{
+	{"", 0, 0, nil},
+	{"3 + ", 4, 4, nil},
+	{"x = 12", 6, 4, nil},
+	{"dou", 3, 0, []string{"double"}},
+	{"1 + doz", 7, 4, []string{"dozen"}},
+	{"doz + 1", 2, 0, []string{"double", "down", "dozen"}},
+	{"io", 2, 0, []string{"iota"}},
+	{")sav", 4, 1, []string{"save"}},
+	{")sa", 3, 1, []string{"sandbox", "save"}},
+	{") ch", 4, 2, []string{"checkpoint"}},
+	{"x )sa", 5, 3, nil},
+}
+
+func TestComplete(t *testing.T) {
+	reset()
+	context := exec.NewContext(&testConf)
+	run.Ivy(context, "op double x = 2*x\ndozen = 12\n", new(bytes.Buffer), new(bytes.Buffer))
+	complete := completer(context)
+	for _, test := range completeTests {
+		start, got := complete([]rune(test.line), test.pos)
+		if start != test.start || !equal(got, test.want) {
+			t.Errorf("complete(%q, %d) = %d %q; want %d %q", test.line, test.pos, start, got, test.start, test.want)
 		}
-		length++
 	}
+}
 
-	// Input ends at tab-indented line.
-	for _, line := range lines[length:] {
-		line = strings.TrimRight(line, " \t")
-		if strings.HasPrefix(line, "\t") {
-			break
+var definitionTests = []struct {
+	line string
+	want bool
+}{
+	{"op f x =", true},
+	{"  op a f b =  ", true},
+	{"op f x = 2*x", false},
+	{"x = 3", false},
+	{"opx =", false},
+}
+
+func TestStartsDefinition(t *testing.T) {
+	for _, test := range definitionTests {
+		if got := startsDefinition(test.line); got != test.want {
+			t.Errorf("startsDefinition(%q) = %t; want %t", test.line, got, test.want)
 		}
-		input = append(input, line)
-		length++
 	}
+}
 
-	// Output ends at non-blank, non-tab-indented line.
-	// Indented "#" is expected blank line in output.
-	for _, line := range lines[length:] {
-		line = strings.TrimRight(line, " \t")
-		if line != "" && !strings.HasPrefix(line, "\t") {
-			break
+func TestFindFile(t *testing.T) {
+	dir1, dir2 := t.TempDir(), t.TempDir()
+	for _, file := range []string{
+		filepath.Join(dir1, "a.ivy"),
+		filepath.Join(dir2, "a.ivy"),
+		filepath.Join(dir2, "b.ivy"),
+	} {
+		if err := ioutil.WriteFile(file, []byte("1\n"), 0666); err != nil {
+			t.Fatal(err)
 		}
-		output = append(output, strings.TrimPrefix(line, "\t"))
-		length++
 	}
-	for len(output) > 0 && output[len(output)-1] == "" {
-		output = output[:len(output)-1]
+	defer os.Setenv("IVYPATH", os.Getenv("IVYPATH"))
+	os.Setenv("IVYPATH", strings.Join([]string{"", dir1, dir2}, string(filepath.ListSeparator)))
+	tests := []struct {
+		name, want string
+	}{
+		{"a.ivy", filepath.Join(dir1, "a.ivy")},
+		{"b.ivy", filepath.Join(dir2, "b.ivy")},
+		{"c.ivy", "c.ivy"},
+		{"testdata/saved", "testdata/saved"},
+		{filepath.Join(dir2, "a.ivy"), filepath.Join(dir2, "a.ivy")},
 	}
-	for i, line := range output {
-		if line == "#" {
-			output[i] = ""
+	for _, test := range tests {
+		if got := value.FindFile(test.name); got != test.want {
+			t.Errorf("FindFile(%q) = %q; want %q", test.name, got, test.want)
 		}
 	}
-
-	return // Will return nil if no more tests exist.
 }
 
 func reset() {
 	testConf.SetFormat("")
+	testConf.SetFloatPrec(256)
 	testConf.SetMaxBits(1e9)
 	testConf.SetMaxDigits(1e4)
 	testConf.SetOrigin(1)
 	testConf.SetPrompt("")
 	testConf.SetBase(0, 0)
 	testConf.SetRandomSeed(0)
+	testConf.SetGlyphs(false)
+	testConf.SetModulus(nil)
+	testConf.SetMachine(false)
+	testConf.SetSandbox(false)
 }
@@ -0,0 +1,124 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+// Logging the interactive session, and replaying the log.
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"robpike.io/ivy/config"
+	"robpike.io/ivy/run"
+	"robpike.io/ivy/value"
+)
+
+// logReader is an io.ByteReader that records each line read
+// from r in the configuration's log, if one is being kept.
+type logReader struct {
+	r    io.ByteReader
+	conf *config.Config
+	line []byte
+}
+
+func (r *logReader) ReadByte() (byte, error) {
+	c, err := r.r.ReadByte()
+	if err == nil {
+		r.line = append(r.line, c)
+	}
+	if (c == '\n' && err == nil) || (err != nil && len(r.line) > 0) {
+		if log := r.conf.Log(); log != nil {
+			log.Input(strings.TrimRight(string(r.line), "\r\n"))
+		}
+		r.line = r.line[:0]
+	}
+	return c, err
+}
+
+// A replayed group of consecutive lines of input, and the output they produced.
+type replayStep struct {
+	line   int      // Line number in the log of the first input.
+	input  []string // Lines of input.
+	output []string // Lines of output, each "> text".
+	errors []string // Lines of error output, each "! text".
+}
+
+// replay executes the input recorded in the named log and reports to w
+// each group of consecutive input lines whose output differs from that
+// recorded. The )log commands in the log are skipped.
+// It returns false if the log could not be read or the output differed.
+func replay(context value.Context, file string, w io.Writer) bool {
+	fd, err := os.Open(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ivy: %s\n", err)
+		return false
+	}
+	defer fd.Close()
+	var steps []*replayStep
+	var step *replayStep
+	scanner := bufio.NewScanner(fd)
+	scanner.Buffer(nil, 1<<30)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		kind, text, ok := config.ParseLog(scanner.Text())
+		if !ok {
+			fmt.Fprintf(os.Stderr, "ivy: %s:%d: not a log record\n", file, lineNum)
+			return false
+		}
+		if kind == config.LogInput && (step == nil || len(step.output)+len(step.errors) > 0) {
+			step = &replayStep{line: lineNum}
+			steps = append(steps, step)
+		} else if step == nil {
+			fmt.Fprintf(os.Stderr, "ivy: %s:%d: output before input\n", file, lineNum)
+			return false
+		}
+		switch kind {
+		case config.LogInput:
+			if !strings.HasPrefix(strings.TrimSpace(text), ")log") {
+				step.input = append(step.input, text)
+			}
+		case config.LogOutput:
+			step.output = append(step.output, "> "+text)
+		case config.LogError:
+			step.errors = append(step.errors, "! "+text)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "ivy: %s\n", err)
+		return false
+	}
+	ok := true
+	for _, step := range steps {
+		stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+		run.Ivy(context, strings.Join(step.input, "\n"), stdout, stderr)
+		got := append(records("> ", stdout), records("! ", stderr)...)
+		want := append(step.output, step.errors...)
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			fmt.Fprintf(w, "%s:%d:\n\t%s\ngot:\n\t%s\nwant:\n\t%s\n",
+				file, step.line,
+				strings.Join(step.input, "\n\t"),
+				strings.Join(got, "\n\t"),
+				strings.Join(want, "\n\t"))
+			ok = false
+		}
+	}
+	return ok
+}
+
+// records returns the lines in the buffer, each with the prefix.
+func records(prefix string, buf *bytes.Buffer) []string {
+	text := strings.TrimSuffix(buf.String(), "\n")
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = prefix + line
+	}
+	return lines
+}
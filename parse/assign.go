@@ -8,6 +8,7 @@ package parse
 // validity checks.
 
 import (
+	"robpike.io/ivy/exec"
 	"robpike.io/ivy/value"
 )
 
@@ -36,6 +37,7 @@ func assignment(context value.Context, b *binary) value.Value {
 	case *index:
 		switch lhs.left.(type) {
 		case *variableExpr:
+			unshare(context, lhs.left.(*variableExpr))
 			value.IndexAssign(context, lhs, lhs.left, lhs.right, b.right, rhs)
 			return Assignment{Value: rhs}
 		case *index:
@@ -52,9 +54,35 @@ func assignment(context value.Context, b *binary) value.Value {
 				last = x.left
 			}
 			fixed := &index{left: last, right: list}
-			value.Errorf("cannot assign to %s; use %v", b.left.ProgString(), fixed.ProgString())
+			value.Errorf("cannot assign to %s; use %v", display(context, b.left), display(context, fixed))
 		}
 	}
-	value.Errorf("cannot assign to %s", b.left.ProgString())
+	value.Errorf("cannot assign to %s", display(context, b.left))
 	panic("not reached")
 }
+
+// unshare makes the variable safe to modify by indexed assignment, which
+// writes in place, by giving it its own copy of its value if a checkpoint
+// holds the value.
+func unshare(context value.Context, v *variableExpr) {
+	c, ok := context.(*exec.Context)
+	if !ok {
+		return
+	}
+	var val value.Value
+	if v.local >= 1 {
+		val = c.Local(v.local)
+	} else {
+		val = c.Global(v.name)
+	}
+	if val == nil {
+		return
+	}
+	if val, copied := c.Writable(val); copied {
+		if v.local >= 1 {
+			c.AssignLocal(v.local, val)
+		} else {
+			c.AssignGlobal(v.name, val)
+		}
+	}
+}
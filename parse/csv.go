@@ -0,0 +1,351 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parse
+
+// Reading and writing matrices as comma- or tab-separated values.
+
+import (
+	"bufio"
+	"encoding/csv"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"robpike.io/ivy/config"
+	"robpike.io/ivy/value"
+)
+
+// separator returns the field separator for the file:
+// tab if its extension is .tsv, otherwise comma.
+func separator(file string) rune {
+	if strings.EqualFold(filepath.Ext(file), ".tsv") {
+		return '\t'
+	}
+	return ','
+}
+
+// importCSV reads the delimited file into the variable name as a matrix
+// with one row per record. A column whose fields are all numbers holds
+// the exact values; any other column becomes as many columns of chars
+// as its widest field needs, padded with blanks. If header is not empty,
+// the first record is stored in that variable as a char matrix with one
+// row per field. If starts is not empty, that variable is set to the
+// index, in the current origin, of the first matrix column of each field.
+func (p *Parser) importCSV(file, name, header, starts string) {
+	conf := p.context.Config()
+	fd, err := os.Open(value.FindFile(file))
+	if err != nil {
+		p.errorf("%s", err)
+	}
+	defer fd.Close()
+	r := csv.NewReader(bufio.NewReader(fd))
+	r.Comma = separator(file)
+	records, err := r.ReadAll()
+	if err != nil {
+		p.errorf("import: %s", err)
+	}
+	var names []string
+	if header != "" {
+		if len(records) == 0 {
+			p.errorf("import: %s: no header", file)
+		}
+		names, records = records[0], records[1:]
+	}
+	if len(records) == 0 {
+		p.errorf("import: %s: no data", file)
+	}
+	nrows, nfields := len(records), len(records[0])
+	columns := make([][]value.Value, 0, nfields)
+	first := make([]int, nfields)
+	for j := 0; j < nfields; j++ {
+		first[j] = len(columns) + conf.Origin()
+		columns = append(columns, importColumn(conf, records, j)...)
+	}
+	data := make([]value.Value, 0, nrows*len(columns))
+	for i := 0; i < nrows; i++ {
+		for _, col := range columns {
+			data = append(data, col[i])
+		}
+	}
+	p.assignImport(name, value.NewMatrix([]int{nrows, len(columns)}, data))
+	if header != "" {
+		p.assignImport(header, value.NewCharMatrix(names))
+	}
+	if starts != "" {
+		p.assignImport(starts, value.NewIntVector(first))
+	}
+}
+
+// importColumn returns field j of the records as one column of numbers
+// or, if any field is not a number, as columns of chars. An empty field
+// in a column of numbers is a missing value and becomes 0.
+func importColumn(conf *config.Config, records [][]string, j int) [][]value.Value {
+	col := make([]value.Value, len(records))
+	numbers := 0
+	for i, rec := range records {
+		field := strings.TrimSpace(rec[j])
+		if field == "" {
+			col[i] = value.Int(0)
+			continue
+		}
+		v, ok := parseNumber(conf, field)
+		if !ok {
+			numbers = 0
+			break
+		}
+		col[i] = v
+		numbers++
+	}
+	if numbers > 0 {
+		return [][]value.Value{col}
+	}
+	width := 1
+	for _, rec := range records {
+		if n := utf8.RuneCountInString(rec[j]); n > width {
+			width = n
+		}
+	}
+	cols := make([][]value.Value, width)
+	for k := range cols {
+		cols[k] = make([]value.Value, len(records))
+	}
+	for i, rec := range records {
+		runes := []rune(rec[j])
+		for k := range cols {
+			cols[k][i] = value.Char(' ')
+			if k < len(runes) {
+				cols[k][i] = value.Char(runes[k])
+			}
+		}
+	}
+	return cols
+}
+
+// parseNumber returns the value of the number in s, using
+// the same parsing as ivy source. It reports whether s is a number.
+func parseNumber(conf *config.Config, s string) (v value.Value, ok bool) {
+	if s == "" {
+		return nil, false
+	}
+	defer func() {
+		if err := recover(); err != nil {
+			if _, isErr := err.(value.Error); !isErr {
+				panic(err)
+			}
+			v, ok = nil, false
+		}
+	}()
+	v, err := value.Parse(conf, s)
+	return v, err == nil
+}
+
+// assignImport sets the global variable name to val.
+func (p *Parser) assignImport(name string, val value.Value) {
+	c := p.context
+	if c.UnaryFn[name] != nil || c.BinaryFn[name] != nil {
+		p.errorf("cannot import into %s; it is an op", name)
+	}
+	c.AssignGlobal(name, val)
+}
+
+// exportCSV writes the value of the variable name, a matrix, vector or
+// scalar, to the delimited file, one record per row. If starts is not
+// empty, that variable holds the index, in the current origin, of the
+// first column of each field, as set by importCSV; otherwise each run
+// of chars in a row is a single field. Text fields are written with
+// trailing blanks removed. If header is not empty, the rows of that
+// variable, a char matrix or vector, are written first as a single
+// record. Every record must have the same number of fields.
+func (p *Parser) exportCSV(file, name, header, starts string) {
+	conf := p.context.Config()
+	var records [][]string
+	if header != "" {
+		var names []string
+		for _, row := range rows(p.exportValue(header)) {
+			names = append(names, strings.Join(fields(conf, row), ""))
+		}
+		records = append(records, names)
+	}
+	data := rows(p.exportValue(name))
+	var first []int
+	if starts != "" {
+		first = fieldStarts(conf, p.exportValue(starts), len(data[0]))
+	}
+	for _, row := range data {
+		var rec []string
+		if first != nil {
+			rec = splitFields(conf, row, first)
+		} else {
+			rec = fields(conf, row)
+		}
+		if len(records) > 0 && len(rec) != len(records[0]) {
+			p.errorf("export: record %d has %d fields; want %d", len(records)+1, len(rec), len(records[0]))
+		}
+		records = append(records, rec)
+	}
+	// "<conf.out>" is a special case for testing.
+	out := conf.Output()
+	if file != "<conf.out>" {
+		fd, err := os.Create(file)
+		if err != nil {
+			p.errorf("%s", err)
+		}
+		defer fd.Close()
+		buf := bufio.NewWriter(fd)
+		defer buf.Flush()
+		out = buf
+	}
+	w := csv.NewWriter(out)
+	w.Comma = separator(file)
+	w.WriteAll(records)
+	if err := w.Error(); err != nil {
+		p.errorf("export: %s", err)
+	}
+}
+
+// exportValue returns the value of the variable name.
+func (p *Parser) exportValue(name string) value.Value {
+	val := p.context.Global(name)
+	if val == nil {
+		p.errorf("export: %s is not a variable", name)
+	}
+	return val
+}
+
+// rows returns the rows of the value, which must be at most a matrix.
+func rows(val value.Value) [][]value.Value {
+	switch val := value.Boxed(val).(type) {
+	case *value.Matrix:
+		shape := val.Shape()
+		if len(shape) != 2 {
+			value.Errorf("export: rank %d matrix", len(shape))
+		}
+		data := val.Data()
+		rows := make([][]value.Value, shape[0])
+		for i := range rows {
+			rows[i] = data[i*shape[1] : (i+1)*shape[1]]
+		}
+		return rows
+	case value.Vector:
+		return [][]value.Value{val}
+	}
+	return [][]value.Value{{val}}
+}
+
+// fields returns the fields of a row to be exported.
+func fields(conf *config.Config, row []value.Value) []string {
+	var fields []string
+	var text strings.Builder
+	inText := false
+	for _, v := range row {
+		if c, ok := v.(value.Char); ok {
+			text.WriteRune(rune(c))
+			inText = true
+			continue
+		}
+		if inText {
+			fields = append(fields, strings.TrimRight(text.String(), " "))
+			text.Reset()
+			inText = false
+		}
+		fields = append(fields, exportNumber(conf, v))
+	}
+	if inText {
+		fields = append(fields, strings.TrimRight(text.String(), " "))
+	}
+	return fields
+}
+
+// fieldStarts returns the column, counting from 0, at which each field
+// starts, given a vector of indexes in the current origin. They must
+// increase, starting at the first of the ncols columns.
+func fieldStarts(conf *config.Config, v value.Value, ncols int) []int {
+	var elems []value.Value
+	switch v := value.Boxed(v).(type) {
+	case value.Vector:
+		elems = v
+	default:
+		elems = []value.Value{v}
+	}
+	first := make([]int, len(elems))
+	for i, elem := range elems {
+		n, ok := elem.(value.Int)
+		if !ok {
+			value.Errorf("export: field start %s is not a small integer", elem.Sprint(conf))
+		}
+		first[i] = int(n) - conf.Origin()
+		if (i == 0 && first[i] != 0) || (i > 0 && first[i] <= first[i-1]) || first[i] >= ncols {
+			value.Errorf("export: bad field starts %s for %d columns", v.Sprint(conf), ncols)
+		}
+	}
+	return first
+}
+
+// splitFields returns the fields of a row to be exported, with each
+// field starting at the corresponding column in first. A field of
+// more than one column must be all chars.
+func splitFields(conf *config.Config, row []value.Value, first []int) []string {
+	fields := make([]string, len(first))
+	for k, start := range first {
+		end := len(row)
+		if k+1 < len(first) {
+			end = first[k+1]
+		}
+		if _, ok := row[start].(value.Char); !ok && end-start == 1 {
+			fields[k] = exportNumber(conf, row[start])
+			continue
+		}
+		var text strings.Builder
+		for _, v := range row[start:end] {
+			c, ok := v.(value.Char)
+			if !ok {
+				value.Errorf("export: field %d mixes numbers and chars", k+1)
+			}
+			text.WriteRune(rune(c))
+		}
+		fields[k] = strings.TrimRight(text.String(), " ")
+	}
+	return fields
+}
+
+// exportNumber returns the text of the number v, exactly if possible:
+// rationals print as decimals if they terminate, otherwise as a/b.
+func exportNumber(conf *config.Config, v value.Value) string {
+	if r, ok := v.(value.BigRat); ok {
+		if s, ok := terminatingDecimal(r.Rat); ok {
+			return s
+		}
+	}
+	var b strings.Builder
+	put(conf, &b, v)
+	return b.String()
+}
+
+// terminatingDecimal returns the exact decimal representation
+// of r, if it has one.
+func terminatingDecimal(r *big.Rat) (string, bool) {
+	den := new(big.Int).Set(r.Denom())
+	digits := den.TrailingZeroBits()
+	den.Rsh(den, digits)
+	five := big.NewInt(5)
+	var fives uint
+	for {
+		q, m := new(big.Int).QuoRem(den, five, new(big.Int))
+		if m.Sign() != 0 {
+			break
+		}
+		den = q
+		fives++
+	}
+	if den.Cmp(big.NewInt(1)) != 0 {
+		return "", false
+	}
+	if fives > digits {
+		digits = fives
+	}
+	return r.FloatString(int(digits)), true
+}
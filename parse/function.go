@@ -212,6 +212,11 @@ func walk(expr value.Expr, assign bool, f func(value.Expr, bool)) {
 	case *binary:
 		walk(e.right, false, f)
 		walk(e.left, e.op == "=", f)
+	case *catch:
+		walk(e.right, false, f)
+		walk(e.left, false, f)
+	case *assert:
+		walk(e.expr, false, f)
 	case *index:
 		for i := len(e.right) - 1; i >= 0; i-- {
 			walk(e.right[i], false, f)
@@ -227,6 +232,7 @@ func walk(expr value.Expr, assign bool, f func(value.Expr, bool)) {
 	case value.BigInt:
 	case value.BigFloat:
 	case value.BigRat:
+	case value.Float:
 	case value.Vector:
 	case *value.Matrix:
 	default:
@@ -14,7 +14,57 @@ var helpLines = []string{
 	"Some functions such as sqrt are irrational. When ivy evaluates an irrational",
 	"function, the result is stored in a high-precision floating-point number (default",
 	"256 bits of mantissa). Thus when using irrational functions, the values have high",
-	"precision but are not exact.",
+	"precision but are not exact. When the answer happens to be rational, however,",
+	"as in sqrt 4/9, 27**1/3 or 4 log 8, sqrt, ** and log find it and the result",
+	"is exact.",
+	"",
+	"To go back from a float to a fraction, cf gives the terms of a continued",
+	"fraction, stopping for a float when they reproduce it, and uncf turns the",
+	"terms into an exact rational: uncf 5 cf pi is 103993/33102. A bestrat B gives",
+	"the nearest rational to B with denominator at most A, so 1000 bestrat pi is",
+	"355/113.",
+	"",
+	"Since vectors cannot nest, factor of a vector gives a matrix with a row of factors",
+	"for each element, padded at the end with 1s so each row's product is the element.",
+	"",
+	"When speed matters more than precision, the machine command (see below)",
+	"switches to fixed-size arithmetic: non-integral results are IEEE 754 float64",
+	"values and integer +, -, * and ** wrap around at 64 bits. Float64 values sit",
+	"between rationals and floats in the type hierarchy, so mixing one with an",
+	"exact value gives a float64 while mixing it with a float gives a float, and",
+	"unary float converts a float64 to a float exactly. Outside machine mode, uncf cf",
+	"turns a float64 back into the simplest fraction that rounds to it. In machine",
+	"mode, complex results have float64 parts and are computed with complex128",
+	"arithmetic, and vector results whose elements are all 64-bit integers or",
+	"float64 values are stored as plain arrays of them, on which elementwise",
+	"arithmetic, comparison and reduction run without per-element overhead.",
+	"",
+	"To bound rounding error, A interval B creates the closed interval [A, B] and",
+	"unary interval gives the smallest interval containing its argument. Arithmetic",
+	"and sqrt on intervals round outward, so the true result always lies within the",
+	"printed bounds; lower and upper extract them. The other elementary functions,",
+	"such as log, exp and sin, compute their bounds with extra precision and widen",
+	"them by a generous margin, but that margin is not proven, so their bounds are",
+	"not guaranteed. Comparing intervals gives 1 or 0 when the answer is certain and",
+	"the interval [0, 1] when it is not; certainly and possibly turn such a result",
+	"into 0 or 1.",
+	"",
+	"Unary json encodes any value losslessly as a JSON string, and unjson decodes",
+	"one: a rational becomes an object {\"num\": 1, \"den\": 3}, a float an object holding",
+	"its shortest exact digits and precision, and a matrix an object holding its shape",
+	"and data in row-major order. Chars and strings become JSON strings. Any JSON number",
+	"decodes exactly, so unjson '0.1' is the rational 1/10. The -json flag prints each",
+	"result as a line of JSON rather than as text.",
+	"",
+	"Programs can read and write files: read B gives the contents of the file named B,",
+	"lines B its lines, and dir B the names in a directory, while A write B and A append B",
+	"store the text A. Relative names are found as by the )get command, and the name \"-\"",
+	"means standard input or output. Since vectors cannot nest, lines and dir return char",
+	"matrices padded with blanks; write and append accept a char matrix, writing each row",
+	"as a line with trailing blanks removed, and return the number of bytes written. The",
+	"sandbox setting forbids file access; see the )sandbox command. As operators, read,",
+	"lines, dir, write and append cannot be used as variable names; programs written for",
+	"earlier versions that assign to them must rename those variables.",
 	"",
 	"Unlike in most other languages, operators always have the same precedence and",
 	"expressions are evaluated in right-associative order. That is, unary operators",
@@ -40,9 +90,30 @@ var helpLines = []string{
 	"After each successful expression evaluation, the result is stored in the variable",
 	"called _ (underscore) so it can be used in the next expression.",
 	"",
+	"Run-time errors can be trapped with the catch keyword. In the expression",
+	"x catch y, the operand x is evaluated and, if that raises an error, y is",
+	"evaluated instead to provide the result. The text of the error message is",
+	"stored as a char vector in the variable _error. Thus (1 / 0) catch 0 is 0,",
+	"and inside a user-defined operator (float solve x) catch _error returns",
+	"either the solution or the reason it failed.",
+	"",
+	"The assert keyword checks that every element of the value of the expression",
+	"that follows it is non-zero, and otherwise raises an error showing the text of",
+	"the expression and its value or, if it is a comparison, the values of both its",
+	"operands: assert 6 == double 3. A char value is an error. The test special",
+	"command runs the user-defined operators that are tests.",
+	"",
 	"The APL operators, adapted from https://en.wikipedia.org/wiki/APL_syntax_and_symbols,",
 	"and their correspondence are listed here. The correspondence is incomplete and inexact.",
 	"",
+	"The APL glyphs in these tables may be typed in place of the ivy names: ⍳5 is",
+	"iota 5, 2×⍳3 is 2*iota 3, and (⍳3)∘.×⍳3 is (iota 3) o.* iota 3. Where APL uses",
+	"one glyph for two operators that ivy names differently, such as ⌈ for ceil and max,",
+	"the glyph's meaning depends on whether it is used as a unary or binary operator.",
+	"A glyph listed in only one table, such as ∣ for abs, is an error in the other",
+	"role; APL's binary ∣ is residue, but with its operands swapped from mod.",
+	"A number may be written with APL's high minus: ¯3 is -3 and 1e¯3 is 1/1000.",
+	"",
 	"Unary operators",
 	"",
 	"\tName              APL   Ivy     Meaning",
@@ -88,6 +159,27 @@ var helpLines = []string{
 	"\tImaginary part    11○B  imag    Imaginary part of a complex number.",
 	"\tPhase angle       12○B  phase   Phase angle (argument) of a complex number.",
 	"\tImaginary         0JB   j       Complex number with zero real part.",
+	"\tGamma                   gamma   Gamma function; (B-1)! for a positive integer B",
+	"\tLog gamma               lgamma  Natural logarithm of abs(gamma B)",
+	"\tError function          erf     erf(B)",
+	"\tCompl. error fn         erfc    1 - erf(B), accurate when B is large",
+	"\tRiemann zeta            zeta    ζ(B); exact for integers B <= 0",
+	"\tIs prime                isprime 1 if B is prime, otherwise 0 (integer only)",
+	"\tNext prime              nextprime The least prime greater than B (integer only)",
+	"\tPrime factors           factor  Prime factors of B in increasing order (matrix for vector B)",
+	"\tEuler's totient         totient Count of integers 1 to B relatively prime to B",
+	"\tInteger square root     isqrt   Greatest integer whose square is <= B",
+	"\tContinued fraction      cf      Terms of the continued fraction of B",
+	"\tFrom continued fraction uncf    Exact value of the continued fraction with terms B",
+	"\tInterval                interval Smallest interval containing B",
+	"\tLower bound             lower   Lower bound of interval B",
+	"\tUpper bound             upper   Upper bound of interval B",
+	"\tCertainly               certainly 1 if every value in B is nonzero, otherwise 0",
+	"\tPossibly                possibly 1 if some value in B is nonzero, otherwise 0",
+	"\tRead file               read    Contents of the file named B, as a string",
+	"\tRead lines              lines   Lines of the file named B, as rows of a char matrix",
+	"\tList directory          dir     Names in the directory B, as rows of a char matrix",
+	"\tExit                    exit    Stop the program with exit status B, from 0 to 255",
 	"",
 	"Binary operators",
 	"",
@@ -145,6 +237,20 @@ var helpLines = []string{
 	"\tBitwise xor                 ^       Bitwise A exclusive or B (integer only)",
 	"\tLeft shift                  <<      A shifted left B bits (integer only)",
 	"\tRight Shift                 >>      A shifted right B bits (integer only)",
+	"\tBeta                        beta    Beta function: gamma(A) * gamma(B) / gamma(A+B)",
+	"\tBessel J                    besselj Bessel function of the first kind of order A at B",
+	"\tBessel Y                    bessely Bessel function of the second kind of order A at B",
+	"\tGreatest common divisor     gcd     Greatest common divisor of A and B (APL's ∨ on integers)",
+	"\tLeast common multiple       lcm     Least common multiple of A and B (APL's ∧ on integers)",
+	"\tModular inverse             modinv  The X in 0 to B-1 for which A*X is 1 modulo B",
+	"\tModular power               powmod  A**E modulo M, where B is the vector E M",
+	"\tInteger root                iroot   Greatest integer whose Ath power is <= B",
+	"\tJacobi symbol               jacobi  Jacobi symbol (A/B) for odd positive B",
+	"\tContinued fraction          cf      The first A terms of the continued fraction of B",
+	"\tBest rational approximation bestrat Nearest rational to B with denominator at most A",
+	"\tInterval                    interval The interval of values from A to B",
+	"\tWrite file                  write   Write the text A to the file named B",
+	"\tAppend to file              append  Append the text A to the file named B",
 	"",
 	"Operators and axis indicator",
 	"",
@@ -164,12 +270,21 @@ var helpLines = []string{
 	"\tCode                    code B  The integer Unicode value of char B",
 	"\tChar                    char B  The character with integer Unicode value B",
 	"\tFloat                   float B The floating-point representation of B",
+	"\tJSON                    json B  The JSON encoding of B, as a string",
+	"\tUnjson                  unjson B The value encoded by the JSON string B",
 	"",
 	"Pre-defined constants",
 	"",
-	"The constants e (base of natural logarithms) and pi (π) are pre-defined to high",
-	"precision, about 3000 decimal digits truncated according to the floating point",
-	"precision setting.",
+	"The constants e (base of natural logarithms), pi (π), euler (the Euler-Mascheroni",
+	"constant γ), phi (the golden ratio φ), and catalan (Catalan's constant) are pre-defined.",
+	"They are computed as needed to the full floating point precision setting, however high.",
+	"Only e and pi are reserved. The others are values only until a variable of the same",
+	"name is assigned, which then takes their place until it is erased.",
+	"",
+	"The special functions gamma, lgamma, erf, erfc, zeta, beta, besselj and bessely",
+	"are also computed to the full precision. All but the order of a Bessel function",
+	"may be complex. Real arguments give real results; lgamma of a real B is the",
+	"logarithm of the magnitude of gamma B.",
 	"",
 	"Character data",
 	"",
@@ -237,12 +352,12 @@ var helpLines = []string{
 	"\tiota 3 sum 4",
 	"\tresult: 1 2 3 4 5 6 7",
 	"",
-	"Example: primes less than N (unary):",
+	"Example: primes less than N (unary; isprime is much faster):",
 	"\top primes N = (not T in T o.* T) sel T = 1 drop iota N",
 	"\tprimes 50",
 	"\tresult: 2 3 5 7 11 13 17 19 23 29 31 37 41 43 47",
 	"",
-	"Example: greatest common divisor (binary):",
+	"Example: greatest common divisor (binary; a slow version of the built-in gcd):",
 	"\top a gcd b =",
 	"\t\ta == b: a",
 	"\t\ta > b: b gcd a-b",
@@ -280,6 +395,49 @@ var helpLines = []string{
 	"\ttotal last",
 	"\tresult: 12 3",
 	"",
+	"When standard input is a terminal, ivy provides line editing with the usual",
+	"Emacs-style keys. The up and down arrows step through the history of input",
+	"lines, which is kept between sessions in the file named by $IVYHISTORY,",
+	"by default ~/.ivy_history. The tab key completes the names of operators,",
+	"variables and, after a right paren, special commands. The lines of a",
+	"multi-line op definition are saved as a single history entry, so recalling",
+	"it brings back the whole definition, with ↵ marking the line breaks.",
+	"",
+	"At startup, ivy runs the file named by $IVYRC, or if that is not set the file",
+	"~/.ivyrc if it exists, unless the -norc flag is given. An error in that file",
+	"is reported and skips the rest of it, but ivy still starts. A relative file",
+	"name given to the -f flag or the )get command that does not exist in the",
+	"current directory is looked up in the directories listed in $IVYPATH, which",
+	"are separated by colons. The )lib command loads a library from that path.",
+	"",
+	"Arguments on the command line after -- are passed to the program, not run as",
+	"files, in the variable args: a char matrix with one argument per row, padded",
+	"with blanks to the length of the longest, so ivy args[1] is the value of a",
+	"numeric first argument. If the first file begins with #!, the remaining",
+	"arguments are passed to it without the --, so a file starting with the line",
+	"#!/usr/bin/env ivy can be executed directly. The unary operator exit stops the",
+	"program with the given status.",
+	"",
+	"Ivy can also process standard input like awk. With the -each flag, ivy runs the",
+	"given expression once for each line of input, with the variable x set to the",
+	"numbers on the line (a scalar or vector) or, if it is not all numbers, its text,",
+	"and nr set to the line number. The -begin and -end expressions run before and",
+	"after the input, so they can accumulate across lines:",
+	"\tseq 1 100 | ivy -begin 's=0' -each 's=s+x' -end 's'",
+	"With the -all flag, x is instead set to all of standard input before -e runs: a",
+	"matrix with a row of numbers per line, a vector if there is one number per line,",
+	"or otherwise a char matrix of the lines, so seq 1 100 | ivy -all -e '+/x' is 5050.",
+	"The -all flag requires -e and cannot be used with -each.",
+	"",
+	"To test ivy programs, ivy -check file.ivy ... runs the examples in the files and",
+	"reports each whose output differs from that expected, exiting with non-zero status",
+	"if any does. Examples are separated by blank lines; each is lines of input followed",
+	"by the expected output, every line indented by a tab, with a tab and # for a blank",
+	"line. Each example runs in a new workspace, starting with the settings given by",
+	"flags, so it should load any library it needs. In a file whose name ends in",
+	"_fail.ivy, every example must fail instead. This is the format of ivy's own",
+	"tests; see testdata/README.",
+	"",
 	"Special commands",
 	"",
 	"Ivy accepts a number of special commands, introduced by a right paren",
@@ -296,9 +454,24 @@ var helpLines = []string{
 	"\t\trespectively.  Base 0 allows C-style input: decimal, with 037 being",
 	"\t\toctal and 0x10 being hexadecimal. If the base is greater than 10,",
 	"\t\tany identifier formed from valid numerals in the base system, such",
-	"\t\tas abe for base 16, is taken to be a number. TODO: To output",
-	"\t\tlarge integers and rationals, base must be one of 0 2 8 10 16.",
-	"\t\tFloats are always printed base 10.",
+	"\t\tas abe for base 16, is taken to be a number. A number in the",
+	"\t\tinput base may have a fraction, as in 0.1 for 1/3 in base 3, but",
+	"\t\tits exponent, if any, is a decimal power of ten. Floats print in",
+	"\t\tthe output base, correctly rounded; where the number is very large",
+	"\t\tor small, d.ddd@e means d.ddd times the base to the (decimal) power e.",
+	"\t) clear",
+	"\t\tRemove all user-defined ops and variables, leaving the",
+	"\t\tconfiguration unchanged.",
+	"\t) copy \"save.ivy\" name ...",
+	"\t\tRead the workspace saved in the named file and copy only the",
+	"\t\tnamed ops and variables from it, leaving the configuration and",
+	"\t\teverything else unchanged. With no names, copy all of them.",
+	"\t\tAn op that calls other ops works only if they are copied too.",
+	"\t\t(Unimplemented on mobile.)",
+	"\t) checkpoint name",
+	"\t\tRecord the variables, user-defined operators and settings under",
+	"\t\tthe name, for rollback and diff. Values are shared, not copied,",
+	"\t\tso a checkpoint is cheap. With no argument, list the checkpoints.",
 	"\t) cpu",
 	"\t\tPrint the duration of the last interactive calculation.",
 	"\t) debug name 0|1",
@@ -307,15 +480,81 @@ var helpLines = []string{
 	"\t) demo",
 	"\t\tRun a line-by-line interactive demo. On mobile platforms,",
 	"\t\tuse the Demo menu option instead.",
+	"\t) diff name [name]",
+	"\t\tList the variables, user-defined operators and settings that",
+	"\t\tchanged between the two named checkpoints or, if only one is",
+	"\t\tnamed, between it and the current state.",
+	"\t) erase name ...",
+	"\t\tRemove the named variables and user-defined ops.",
+	"\t) erase op f",
+	"\t) erase op f x",
+	"\t) erase op x f y",
+	"\t\tRemove only the user-defined op f, never a variable. As in the",
+	"\t\top declaration, f x names only the unary form and x f y only",
+	"\t\tthe binary form; the argument names are ignored.",
+	"\t) export \"data.csv\" name [header [starts]]",
+	"\t\tWrite the matrix (or vector) in the variable name to the named",
+	"\t\tfile, one record per row, as comma-separated values, or as",
+	"\t\ttab-separated values if the file name ends in .tsv. Numbers are",
+	"\t\twritten exactly: terminating rationals as decimals, others as a/b.",
+	"\t\tIf a header variable is named, its rows are written first, as",
+	"\t\tthe header record; \"\" names no header. If a starts variable is",
+	"\t\tnamed, it holds the index of the first column of each field,",
+	"\t\tas set by import; otherwise each run of chars in a row is one",
+	"\t\tfield. Text is written without trailing blanks. Every record",
+	"\t\tmust have the same number of fields. (Unimplemented on mobile.)",
 	"\t) format \"\"",
 	"\t\tSet the format for printing values. If empty, the output is printed",
 	"\t\tusing the output base. If non-empty, the format determines the",
 	"\t\tbase used in printing. The format is in the style of golang.org/pkg/fmt.",
 	"\t\tFor floating-point formats, flags and width are ignored.",
+	"\t\tTwo formats are ivy's own, for exact values: \"%r\" prints rationals",
+	"\t\tas decimals with the repeating part in parentheses, 0.1(6), and",
+	"\t\t\"%m\" prints them as mixed numbers, 1 1/2; in vectors and matrices,",
+	"\t\twhere a blank separates elements, the parts are joined by their",
+	"\t\tsign, 1+1/2. Numbers in files read by import and in stream input",
+	"\t\tmay be written as repeating decimals: 0.1(6) is 1/6.",
+	"\t) glyphs 0",
+	"\t\tIf set, show the definitions of user-defined operators, and the",
+	"\t\texpressions quoted in error messages such as a failed assert,",
+	"\t\tusing APL glyphs for the built-in operators (see the op command).",
 	"\t) get \"save.ivy\"",
 	"\t\tRead input from the named file; return to interactive execution",
 	"\t\tafterwards. If no file is specified, read from \"save.ivy\".",
 	"\t\t(Unimplemented on mobile.)",
+	"\t) import \"data.csv\" name [header [starts]]",
+	"\t\tRead the named comma-separated (or, for .tsv, tab-separated)",
+	"\t\tfile, found as for the get command, into the variable name as a",
+	"\t\tmatrix with one row per record. Quoted fields are supported. A",
+	"\t\tcolumn whose fields all parse as ivy numbers holds their exact",
+	"\t\tvalues, so decimals become rationals, and its empty fields are",
+	"\t\tmissing values that become 0; any other column becomes",
+	"\t\tas many columns of chars as its widest field, padded with blanks.",
+	"\t\tIf a header variable is named, the first record is stored in it",
+	"\t\tas a char matrix with one row per field; \"\" names no header. If",
+	"\t\ta starts variable is named, it is set to the index of the first",
+	"\t\tmatrix column of each field, so (transp x)[starts[i]] is the",
+	"\t\tstart of field i, and export with it writes the fields back",
+	"\t\tunchanged. Indexes are in the current origin.",
+	"\t\t(Unimplemented on mobile.)",
+	"\t) lib name",
+	"\t\tRead the library file name.ivy, found as for the get command,",
+	"\t\tunless it has already been loaded; a library whose loading failed",
+	"\t\tis read again. The name may be an identifier or a quoted string.",
+	"\t\tWith no argument, list the loaded libraries.",
+	"\t\t(Unimplemented on mobile.)",
+	"\t) log \"session.log\"",
+	"\t\tRecord each line of input and of output and error output, with",
+	"\t\tthe time, in the named file. ) log off stops recording; with no",
+	"\t\targument, print the name of the file. The -log flag records the",
+	"\t\twhole session, and ivy -replay session.log executes the recorded",
+	"\t\tinput and reports any output that differs from that recorded.",
+	"\t\t(Unimplemented on mobile.)",
+	"\t) machine 0",
+	"\t\tIf set, compute using machine arithmetic: integers wrap around at",
+	"\t\t64 bits, numbers that would be rationals or floats, including",
+	"\t\tnon-integral and very large number literals, are float64, and",
+	"\t\tcomplex numbers have float64 parts.",
 	"\t) maxbits 1e6",
 	"\t\tTo avoid consuming too much memory, if an integer result would",
 	"\t\trequire more than this many bits to store, abort the calculation.",
@@ -327,6 +566,13 @@ var helpLines = []string{
 	"\t) maxstack 1e5",
 	"\t\tTo avoid using too much stack, the number of nested active calls to",
 	"\t\tuser-defined operators is limited to maxstack.",
+	"\t) modulus 0",
+	"\t\tIf the modulus is non-zero, the arithmetic operators + - * / and **,",
+	"\t\tincluding their uses in inner and outer products, and unary - and /,",
+	"\t\treduce every integer result to the range [0, modulus). Division",
+	"\t\tmultiplies by the modular inverse, as does exponentiation to a",
+	"\t\tnegative power. Other operators, and constants, are unaffected,",
+	"\t\tso indexes, shapes and counts such as iota 10 mean what they say.",
 	"\t) op X",
 	"\t\tIf X is absent, list all user-defined operators. Otherwise,",
 	"\t\tshow the definition of the user-defined operator X. Inside the",
@@ -339,6 +585,20 @@ var helpLines = []string{
 	"\t\tThe value is in bits. The exponent always has 32 bits.",
 	"\t) prompt \"\"",
 	"\t\tSet the interactive prompt.",
+	"\t) restore \"save.snap\"",
+	"\t\tReplace the workspace with the one in the named snapshot file,",
+	"\t\twritten by the snapshot command. If no file is specified, restore",
+	"\t\tfrom \"save.snap\".",
+	"\t\t(Unimplemented on mobile.)",
+	"\t) rollback name",
+	"\t\tReturn the variables, user-defined operators and settings to",
+	"\t\ttheir state at the named checkpoint, which remains available.",
+	"\t) sandbox 0",
+	"\t\tIf set, the file operators read, lines, dir, write and append",
+	"\t\tfail for any file but \"-\", standard input or output, as do the",
+	"\t\tspecial commands that read or write files: copy, export, get,",
+	"\t\timport, lib, log, restore, save, snapshot and test with a file.",
+	"\t\tOnce set, it cannot be cleared; the -sandbox flag sets it at startup.",
 	"\t) save \"save.ivy\"",
 	"\t\tWrite definitions of user-defined operators and variables to the",
 	"\t\tnamed file, as ivy textual source. If no file is specified, save to",
@@ -346,6 +606,27 @@ var helpLines = []string{
 	"\t\t(Unimplemented on mobile.)",
 	"\t) seed 0",
 	"\t\tSet the seed for the ? operator.",
+	"\t) snapshot \"save.snap\"",
+	"\t\tWrite the configuration, user-defined operators and variables",
+	"\t\tto the named file in a binary format that, unlike save, holds",
+	"\t\tevery value exactly, including the precision and full mantissa",
+	"\t\tof floats, and is quick to restore even for large matrices.",
+	"\t\tIf no file is specified, write to \"save.snap\".",
+	"\t\t(Unimplemented on mobile.)",
+	"\t) test \"file.ivy\"",
+	"\t\tRead the named file, as with get, if one is given, then run each",
+	"\t\tunary user-defined operator whose name begins with test, in the",
+	"\t\torder they were defined, applied to an empty vector. A test fails",
+	"\t\tif it raises an error, for instance with assert. Print the result",
+	"\t\tand time of each test and then the numbers passed and failed,",
+	"\t\twhich is an error if any failed.",
+	"\t) undo",
+	"\t\tReturn the variables, user-defined operators and settings to",
+	"\t\ttheir state before the previous line of input. Since the undo",
+	"\t\tis itself a line of input, a second undo cancels the first.",
+	"\t) vars",
+	"\t\tList the variables with their types and, for vectors and",
+	"\t\tmatrices, their shapes.",
 }
 
 type helpIndexPair struct {
@@ -353,99 +634,136 @@ type helpIndexPair struct {
 }
 
 var helpUnary = map[string]helpIndexPair{
-	"?":      {43, 43},
-	"ceil":   {44, 44},
-	"floor":  {45, 45},
-	"rho":    {46, 46},
-	"not":    {47, 47},
-	"abs":    {48, 48},
-	"iota":   {49, 49},
-	"**":     {50, 50},
-	"-":      {51, 51},
-	"+":      {52, 52},
-	"sgn":    {53, 53},
-	"/":      {54, 54},
-	",":      {55, 55},
-	"log":    {58, 58},
-	"rot":    {59, 59},
-	"flip":   {60, 60},
-	"up":     {61, 61},
-	"down":   {62, 62},
-	"ivy":    {63, 63},
-	"text":   {64, 64},
-	"transp": {65, 65},
-	"!":      {66, 66},
-	"^":      {67, 67},
-	"sqrt":   {68, 68},
-	"sin":    {69, 69},
-	"cos":    {70, 70},
-	"tan":    {71, 71},
-	"asin":   {72, 72},
-	"acos":   {73, 73},
-	"atan":   {74, 74},
-	"sinh":   {75, 75},
-	"cosh":   {76, 76},
-	"tanh":   {77, 77},
-	"asinh":  {78, 78},
-	"acosh":  {79, 79},
-	"atanh":  {80, 80},
-	"real":   {81, 81},
-	"imag":   {82, 82},
-	"phase":  {83, 83},
-	"j":      {84, 84},
-	"code":   {158, 158},
-	"char":   {159, 159},
-	"float":  {160, 160},
+	"?":         {114, 114},
+	"ceil":      {115, 115},
+	"floor":     {116, 116},
+	"rho":       {117, 117},
+	"not":       {118, 118},
+	"abs":       {119, 119},
+	"iota":      {120, 120},
+	"**":        {121, 121},
+	"-":         {122, 122},
+	"+":         {123, 123},
+	"sgn":       {124, 124},
+	"/":         {125, 125},
+	",":         {126, 126},
+	"log":       {129, 129},
+	"rot":       {130, 130},
+	"flip":      {131, 131},
+	"up":        {132, 132},
+	"down":      {133, 133},
+	"ivy":       {134, 134},
+	"text":      {135, 135},
+	"transp":    {136, 136},
+	"!":         {137, 137},
+	"^":         {138, 138},
+	"sqrt":      {139, 139},
+	"sin":       {140, 140},
+	"cos":       {141, 141},
+	"tan":       {142, 142},
+	"asin":      {143, 143},
+	"acos":      {144, 144},
+	"atan":      {145, 145},
+	"sinh":      {146, 146},
+	"cosh":      {147, 147},
+	"tanh":      {148, 148},
+	"asinh":     {149, 149},
+	"acosh":     {150, 150},
+	"atanh":     {151, 151},
+	"real":      {152, 152},
+	"imag":      {153, 153},
+	"phase":     {154, 154},
+	"j":         {155, 155},
+	"gamma":     {156, 156},
+	"lgamma":    {157, 157},
+	"erf":       {158, 158},
+	"erfc":      {159, 159},
+	"zeta":      {160, 160},
+	"isprime":   {161, 161},
+	"nextprime": {162, 162},
+	"factor":    {163, 163},
+	"totient":   {164, 164},
+	"isqrt":     {165, 165},
+	"cf":        {166, 166},
+	"uncf":      {167, 167},
+	"interval":  {168, 168},
+	"lower":     {169, 169},
+	"upper":     {170, 170},
+	"certainly": {171, 171},
+	"possibly":  {172, 172},
+	"read":      {173, 173},
+	"lines":     {174, 174},
+	"dir":       {175, 175},
+	"exit":      {176, 176},
+	"code":      {264, 264},
+	"char":      {265, 265},
+	"float":     {266, 266},
+	"json":      {267, 267},
+	"unjson":    {268, 268},
 }
 
 var helpBinary = map[string]helpIndexPair{
-	"+":      {89, 89},
-	"-":      {90, 90},
-	"*":      {91, 91},
-	"/":      {92, 94},
-	"**":     {95, 95},
-	"?":      {96, 96},
-	"in":     {97, 97},
-	"max":    {98, 98},
-	"min":    {99, 99},
-	"rho":    {100, 100},
-	"take":   {101, 101},
-	"drop":   {102, 102},
-	"decode": {103, 103},
-	"encode": {104, 104},
-	"mod":    {106, 107},
-	",":      {108, 108},
-	"fill":   {109, 110},
-	"sel":    {111, 112},
-	"iota":   {113, 114},
-	"rot":    {116, 116},
-	"flip":   {117, 117},
-	"log":    {118, 118},
-	"text":   {119, 123},
-	"transp": {124, 124},
-	"!":      {125, 125},
-	"<":      {126, 126},
-	"<=":     {127, 127},
-	"==":     {128, 128},
-	">=":     {129, 129},
-	">":      {130, 130},
-	"!=":     {131, 131},
-	"or":     {132, 132},
-	"and":    {133, 133},
-	"nor":    {134, 134},
-	"nand":   {135, 135},
-	"xor":    {136, 136},
-	"&":      {137, 137},
-	"|":      {138, 138},
-	"^":      {139, 139},
-	"<<":     {140, 140},
-	">>":     {141, 141},
+	"+":        {181, 181},
+	"-":        {182, 182},
+	"*":        {183, 183},
+	"/":        {184, 186},
+	"**":       {187, 187},
+	"?":        {188, 188},
+	"in":       {189, 189},
+	"max":      {190, 190},
+	"min":      {191, 191},
+	"rho":      {192, 192},
+	"take":     {193, 193},
+	"drop":     {194, 194},
+	"decode":   {195, 195},
+	"encode":   {196, 196},
+	"mod":      {198, 199},
+	",":        {200, 200},
+	"fill":     {201, 202},
+	"sel":      {203, 204},
+	"iota":     {205, 206},
+	"rot":      {208, 208},
+	"flip":     {209, 209},
+	"log":      {210, 210},
+	"text":     {211, 215},
+	"transp":   {216, 216},
+	"!":        {217, 217},
+	"<":        {218, 218},
+	"<=":       {219, 219},
+	"==":       {220, 220},
+	">=":       {221, 221},
+	">":        {222, 222},
+	"!=":       {223, 223},
+	"or":       {224, 224},
+	"and":      {225, 225},
+	"nor":      {226, 226},
+	"nand":     {227, 227},
+	"xor":      {228, 228},
+	"&":        {229, 229},
+	"|":        {230, 230},
+	"^":        {231, 231},
+	"<<":       {232, 232},
+	">>":       {233, 233},
+	"beta":     {234, 234},
+	"besselj":  {235, 235},
+	"bessely":  {236, 236},
+	"gcd":      {237, 237},
+	"lcm":      {238, 238},
+	"modinv":   {239, 239},
+	"powmod":   {240, 240},
+	"iroot":    {241, 241},
+	"jacobi":   {242, 242},
+	"cf":       {243, 243},
+	"bestrat":  {244, 244},
+	"interval": {245, 245},
+	"write":    {246, 246},
+	"append":   {247, 247},
 }
 
 var helpAxis = map[string]helpIndexPair{
-	"/":  {146, 146},
-	"\\": {148, 148},
-	".":  {150, 150},
-	"o.": {151, 151},
-	"j":  {153, 153},
+	"/":  {252, 252},
+	"\\": {254, 254},
+	".":  {256, 256},
+	"o.": {257, 257},
+	"j":  {259, 259},
 }
@@ -7,6 +7,7 @@ package parse // import "robpike.io/ivy/parse"
 import (
 	"bytes"
 	"fmt"
+	"math/big"
 	"strconv"
 	"strings"
 
@@ -24,6 +25,8 @@ func tree(e interface{}) string {
 		return fmt.Sprintf("<bigint %s>", e)
 	case value.BigRat:
 		return fmt.Sprintf("<rat %s>", e)
+	case value.Float:
+		return fmt.Sprintf("<float64 %s>", e)
 	case value.Complex:
 		return fmt.Sprintf("<complex %s>", e)
 	case sliceExpr:
@@ -44,6 +47,10 @@ func tree(e interface{}) string {
 		return fmt.Sprintf("(%s %s %s)", tree(e.left), e.op, tree(e.right))
 	case conditional:
 		return tree(e.binary)
+	case *catch:
+		return fmt.Sprintf("(%s catch %s)", tree(e.left), tree(e.right))
+	case *assert:
+		return fmt.Sprintf("(assert %s)", tree(e.expr))
 	case *index:
 		s := fmt.Sprintf("(%s[", tree(e.left))
 		for i, v := range e.right {
@@ -182,7 +189,7 @@ func (e *variableExpr) ProgString() string {
 // may require parentheses around it when printed to maintain correct evaluation order.
 func isCompound(x interface{}) bool {
 	switch x := x.(type) {
-	case value.Char, value.Int, value.BigInt, value.BigRat, value.BigFloat, value.Vector, value.Matrix:
+	case value.Char, value.Int, value.BigInt, value.BigRat, value.Float, value.BigFloat, value.Vector, value.Matrix:
 		return false
 	case sliceExpr, *variableExpr:
 		return false
@@ -276,6 +283,125 @@ func (c conditional) Operands() (left, right value.Expr) {
 	return c.left, c.right
 }
 
+// catch is an error trap: expression "catch" expression.
+// If evaluating the left operand raises an error, the right
+// operand is evaluated instead, with the error message stored
+// as a char vector in the global variable _error.
+type catch struct {
+	left  value.Expr
+	right value.Expr
+}
+
+func (c *catch) ProgString() string {
+	var left string
+	if isCompound(c.left) {
+		left = fmt.Sprintf("(%s)", c.left.ProgString())
+	} else {
+		left = c.left.ProgString()
+	}
+	return fmt.Sprintf("%s catch %s", left, c.right.ProgString())
+}
+
+func (c *catch) Eval(context value.Context) value.Value {
+	v, err := try(context, c.left)
+	if err == nil {
+		return v
+	}
+	context.AssignGlobal("_error", value.NewCharVector(err.Error()))
+	return c.right.Eval(context)
+}
+
+// assert is an assertion: "assert" expression.
+// If the value of the expression is not all true (non-zero), an error
+// results. If the expression is a comparison, the error reports the
+// values of both its operands.
+type assert struct {
+	expr value.Expr
+}
+
+func (a *assert) ProgString() string {
+	return fmt.Sprintf("assert %s", a.expr.ProgString())
+}
+
+func (a *assert) Eval(context value.Context) value.Value {
+	var v, left, right value.Value
+	if b, ok := a.expr.(*binary); ok && isComparison(b.op) {
+		// Evaluate the operands here, as binary.Eval does, to report them.
+		right = b.right.Eval(context).Inner()
+		left = b.left.Eval(context)
+		v = context.EvalBinary(left, b.op, right)
+	} else {
+		v = a.expr.Eval(context).Inner()
+	}
+	conf := context.Config()
+	if a.allTrue(context, v) {
+		return v
+	}
+	if left != nil {
+		value.Errorf("assert failed: %s: left %s, right %s", display(context, a.expr), left.Inner().Sprint(conf), right.Sprint(conf))
+	}
+	value.Errorf("assert failed: %s: %s", display(context, a.expr), v.Sprint(conf))
+	panic("not reached")
+}
+
+// display returns the program text of x for use in a message, spelled
+// with APL glyphs if the glyphs setting is on.
+func display(context value.Context, x value.Expr) string {
+	if context.Config().Glyphs() {
+		return scan.Glyphs(x.ProgString())
+	}
+	return x.ProgString()
+}
+
+// isComparison reports whether op is a comparison operator.
+func isComparison(op string) bool {
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+// allTrue reports whether every element of v is non-zero.
+// Chars are neither true nor false, so they are an error.
+func (a *assert) allTrue(context value.Context, v value.Value) bool {
+	var elems []value.Value
+	switch v := value.Boxed(v).(type) {
+	case value.Vector:
+		elems = v
+	case *value.Matrix:
+		elems = v.Data()
+	default:
+		elems = []value.Value{v}
+	}
+	for _, elem := range elems {
+		if _, ok := elem.(value.Char); ok {
+			value.Errorf("assert %s: non-numeric value %s", display(context, a.expr), v.Sprint(context.Config()))
+		}
+		if context.EvalBinary(elem, "!=", value.Int(0)) != value.Int(1) {
+			return false
+		}
+	}
+	return true
+}
+
+// try evaluates expr, recovering from any run-time error it raises.
+func try(context value.Context, expr value.Expr) (v value.Value, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			switch e := e.(type) {
+			case value.Error:
+				err = e
+			case big.ErrNaN: // Floating point error from math/big.
+				err = e
+			default:
+				panic(e)
+			}
+		}
+	}()
+	return expr.Eval(context).Inner(), nil
+}
+
 // Parser stores the state for the ivy parser.
 type Parser struct {
 	scanner  *scan.Scanner
@@ -446,11 +572,18 @@ func (p *Parser) statementList() ([]value.Expr, bool) {
 // expr
 //	operand
 //	operand binop expr
+//	operand "catch" expr
 func (p *Parser) expr() value.Expr {
 	tok := p.next()
 	expr := p.operand(tok, true)
 	tok = p.peek()
 	switch tok.Type {
+	case scan.Catch:
+		p.next()
+		return &catch{
+			left:  expr,
+			right: p.expr(),
+		}
 	case scan.EOF, scan.RightParen, scan.RightBrack, scan.Semicolon, scan.Colon:
 		return expr
 	case scan.Identifier:
@@ -472,12 +605,16 @@ func (p *Parser) expr() value.Expr {
 				right: p.expr(),
 			}
 		}
-		p.errorf("cannot assign to %s", expr.ProgString())
+		p.errorf("cannot assign to %s", display(p.context, expr))
 	case scan.Operator:
 		p.next()
+		op, ok := scan.BinaryName(tok.Text)
+		if !ok {
+			p.errorf("%s has no binary form", tok.Text)
+		}
 		return &binary{
 			left:  expr,
-			op:    tok.Text,
+			op:    op,
 			right: p.expr(),
 		}
 	}
@@ -492,12 +629,17 @@ func (p *Parser) expr() value.Expr {
 //	vector
 //	operand [ Expr ]...
 //	unop Expr
+//	"assert" Expr
 func (p *Parser) operand(tok scan.Token, indexOK bool) value.Expr {
 	var expr value.Expr
 	switch tok.Type {
 	case scan.Operator:
+		op, ok := scan.UnaryName(tok.Text)
+		if !ok {
+			p.errorf("%s has no unary form", tok.Text)
+		}
 		expr = &unary{
-			op:    tok.Text,
+			op:    op,
 			right: p.expr(),
 		}
 	case scan.Identifier:
@@ -511,6 +653,10 @@ func (p *Parser) operand(tok scan.Token, indexOK bool) value.Expr {
 		fallthrough
 	case scan.Number, scan.Rational, scan.String, scan.LeftParen:
 		expr = p.numberOrVector(tok)
+	case scan.Assert:
+		expr = &assert{
+			expr: p.expr(),
+		}
 	default:
 		p.errorf("unexpected %s", tok)
 	}
@@ -16,6 +16,7 @@ import (
 
 	"robpike.io/ivy/config"
 	"robpike.io/ivy/exec"
+	"robpike.io/ivy/scan"
 	"robpike.io/ivy/value"
 )
 
@@ -79,9 +80,53 @@ func save(c *exec.Context, file string) {
 	fmt.Fprintf(out, ")origin %d\n", conf.Origin())
 	fmt.Fprintf(out, ")prompt %q\n", conf.Prompt())
 	fmt.Fprintf(out, ")format %q\n", conf.Format())
+	if conf.Machine() {
+		// Set first so float64 values read back as float64.
+		fmt.Fprintf(out, ")machine 1\n")
+	}
+	// Turn off modular arithmetic while the values are read back.
+	modulus := conf.Modulus()
+	if modulus != nil {
+		fmt.Fprintf(out, ")modulus 0\n")
+	}
 	conf.SetBase(10, 10)
 
 	// Ops.
+	saveOps(c, out)
+
+	// Global variables.
+	syms := c.Globals
+	if len(syms) > 0 {
+		// Set the base strictly to 10 for output.
+		fmt.Fprintf(out, "# Set base 10 for parsing numbers.\n)base 10\n")
+		// Sort the names for consistent output.
+		sorted := sortSyms(syms)
+		for _, sym := range sorted {
+			// The constants are generated.
+			if value.IsConstant(sym.name) {
+				continue
+			}
+			fmt.Fprintf(out, "%s = ", sym.name)
+			put(conf, out, sym.val)
+			fmt.Fprint(out, "\n")
+		}
+	}
+
+	// Now we can set the modulus and base.
+	if modulus != nil {
+		fmt.Fprintf(out, ")modulus %s\n", modulus)
+	}
+	fmt.Fprintf(out, ")ibase %d\n", ibase)
+	fmt.Fprintf(out, ")obase %d\n", obase)
+
+	// Restore the configuration's own base.
+	conf.SetBase(ibase, obase)
+}
+
+// saveOps writes the definitions of the ops to out as source text,
+// in the order they were defined, preceded where necessary by
+// declarations of ops they reference that are defined later.
+func saveOps(c *exec.Context, out io.Writer) {
 	printed := make(map[exec.OpDef]bool)
 	for _, def := range c.Defs {
 		var fn *exec.Function
@@ -108,31 +153,6 @@ func save(c *exec.Context, file string) {
 		}
 		fmt.Fprintln(out, s)
 	}
-
-	// Global variables.
-	syms := c.Globals
-	if len(syms) > 0 {
-		// Set the base strictly to 10 for output.
-		fmt.Fprintf(out, "# Set base 10 for parsing numbers.\n)base 10\n")
-		// Sort the names for consistent output.
-		sorted := sortSyms(syms)
-		for _, sym := range sorted {
-			// pi and e are generated
-			if sym.name == "pi" || sym.name == "e" {
-				continue
-			}
-			fmt.Fprintf(out, "%s = ", sym.name)
-			put(conf, out, sym.val)
-			fmt.Fprint(out, "\n")
-		}
-	}
-
-	// Now we can set the base.
-	fmt.Fprintf(out, ")ibase %d\n", ibase)
-	fmt.Fprintf(out, ")obase %d\n", obase)
-
-	// Restore the configuration's own base.
-	conf.SetBase(ibase, obase)
 }
 
 // saveSym holds a variable's name and value so we can sort them for saving.
@@ -158,9 +178,68 @@ func sortSyms(syms map[string]value.Value) []saveSym {
 	return s
 }
 
+// copyFrom reads the workspace saved in the named file and copies the named
+// ops and variables from it into the current context. If no names are given,
+// it copies all of them. Unlike )get, it leaves the configuration alone.
+// An op that calls other ops will work only if they are copied too.
+func (p *Parser) copyFrom(file string, names []string) {
+	// Run the file in a scratch context with its own configuration,
+	// so the settings it makes do not affect ours.
+	conf := *p.context.Config()
+	conf.SetOutput(io.Discard)
+	scratch := exec.NewContext(&conf).(*exec.Context)
+	file = value.FindFile(file)
+	fd, err := os.Open(file)
+	if err != nil {
+		p.errorf("%s", err)
+	}
+	defer fd.Close()
+	parser := NewParser(file, scan.New(scratch, file, bufio.NewReader(fd)), scratch)
+	if parser.runUntilError(file) != io.EOF {
+		p.errorf("copy: cannot read workspace %s", file)
+	}
+	if names == nil {
+		for _, def := range scratch.Defs {
+			names = append(names, def.Name)
+		}
+		for _, sym := range sortSyms(scratch.Globals) {
+			if !value.IsConstant(sym.name) && sym.name != "_" {
+				names = append(names, sym.name)
+			}
+		}
+	}
+	// Check all the names before copying any.
+	for _, name := range names {
+		if scratch.Globals[name] == nil && scratch.UnaryFn[name] == nil && scratch.BinaryFn[name] == nil {
+			p.errorf("%q not defined in %s", name, file)
+		}
+	}
+	c := p.context
+	for _, def := range scratch.Defs {
+		for _, name := range names {
+			if def.Name != name {
+				continue
+			}
+			if def.IsBinary {
+				c.Define(scratch.BinaryFn[name])
+			} else {
+				c.Define(scratch.UnaryFn[name])
+			}
+		}
+	}
+	for _, name := range names {
+		if val := scratch.Globals[name]; val != nil {
+			if c.UnaryFn[name] != nil || c.BinaryFn[name] != nil {
+				p.errorf("cannot copy variable %s; it is an op", name)
+			}
+			c.AssignGlobal(name, val)
+		}
+	}
+}
+
 // put writes to out a version of the value that will recreate it when parsed.
 func put(conf *config.Config, out io.Writer, val value.Value) {
-	switch val := val.(type) {
+	switch val := value.Boxed(val).(type) {
 	case value.Char:
 		fmt.Fprintf(out, "%q", rune(val))
 	case value.Int:
@@ -169,6 +248,8 @@ func put(conf *config.Config, out io.Writer, val value.Value) {
 		fmt.Fprintf(out, "%d", val.Int)
 	case value.BigRat:
 		fmt.Fprintf(out, "%d/%d", val.Num(), val.Denom())
+	case value.Float:
+		fmt.Fprint(out, val.ProgString())
 	case value.BigFloat:
 		if val.Sign() == 0 || val.IsInf() {
 			// These have prec 0 and are easy.
@@ -181,6 +262,12 @@ func put(conf *config.Config, out io.Writer, val value.Value) {
 		// Probably not important but it would be nice to fix it.
 		digits := int(float64(val.Prec()) * 0.301029995664) // 10 log 2.
 		fmt.Fprintf(out, "%.*g", digits+1, val.Float)       // Add another digit to be sure.
+	case value.Interval:
+		fmt.Fprint(out, val.ProgString())
+	case value.Complex:
+		put(conf, out, val.Real())
+		fmt.Fprint(out, "j")
+		put(conf, out, val.Imag())
 	case value.Vector:
 		if val.AllChars() {
 			fmt.Fprintf(out, "%q", val.Sprint(conf))
@@ -0,0 +1,176 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parse
+
+// Saving and restoring the exact state of the workspace in binary.
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+
+	"robpike.io/ivy/exec"
+	"robpike.io/ivy/scan"
+	"robpike.io/ivy/value"
+)
+
+/*
+A snapshot holds, in order:
+	the magic string and the format version
+	the configuration
+	the op definitions, as the source text written by )save
+	the global variables, each a name and a binary value
+Unlike )save, values are stored exactly: a float keeps its precision and
+every bit of its mantissa. Ops are still stored as text, since their
+bodies are parse trees, but they are parsed with the configuration that
+printed them, and in definition order, so they parse as they did before.
+Any change to the layout must increment snapshotVersion.
+*/
+
+const (
+	snapshotMagic   = "ivy snapshot"
+	snapshotVersion = 1
+)
+
+const defaultSnapshot = "save.snap"
+
+// snapshot writes the state of the workspace to the named file.
+func snapshot(c *exec.Context, file string) {
+	conf := c.Config()
+	fd, err := os.Create(file)
+	if err != nil {
+		value.Errorf("%s", err)
+	}
+	defer fd.Close()
+	w := value.NewSnapshotWriter(fd)
+	w.String(snapshotMagic)
+	w.Uvarint(snapshotVersion)
+
+	// Configuration.
+	ibase, obase := conf.Base()
+	w.Uvarint(uint64(conf.FloatPrec()))
+	w.Uvarint(uint64(conf.MaxBits()))
+	w.Uvarint(uint64(conf.MaxDigits()))
+	w.Uvarint(uint64(conf.MaxStack()))
+	w.Varint(int64(conf.Origin()))
+	w.String(conf.Prompt())
+	w.String(conf.Format())
+	w.Varint(int64(ibase))
+	w.Varint(int64(obase))
+	w.Uvarint(boolBit(conf.Machine()))
+	w.Uvarint(boolBit(conf.Glyphs()))
+	w.BigInt(conf.Modulus())
+	w.Varint(conf.RandomSeed())
+
+	// Ops, printed in base 10.
+	var ops strings.Builder
+	conf.SetBase(10, 10)
+	saveOps(c, &ops)
+	conf.SetBase(ibase, obase)
+	w.String(ops.String())
+
+	// Global variables.
+	var syms []saveSym
+	for _, sym := range sortSyms(c.Globals) {
+		// The constants are generated.
+		if !value.IsConstant(sym.name) {
+			syms = append(syms, sym)
+		}
+	}
+	w.Uvarint(uint64(len(syms)))
+	for _, sym := range syms {
+		w.String(sym.name)
+		w.Value(sym.val)
+	}
+
+	if err := w.Flush(); err != nil {
+		value.Errorf("snapshot: %s", err)
+	}
+	if err := fd.Close(); err != nil {
+		value.Errorf("snapshot: %s", err)
+	}
+}
+
+func boolBit(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// restore replaces the workspace with the one in the named snapshot file.
+// The file is read completely before the workspace is changed, so if it
+// is not a valid snapshot the workspace is left alone.
+func (p *Parser) restore(file string) {
+	file = value.FindFile(file)
+	fd, err := os.Open(file)
+	if err != nil {
+		p.errorf("%s", err)
+	}
+	defer fd.Close()
+	r := value.NewSnapshotReader(fd, file)
+	if r.String() != snapshotMagic {
+		p.errorf("restore: %s is not an ivy snapshot", file)
+	}
+	if v := r.Uvarint(); v != snapshotVersion {
+		p.errorf("restore: %s: unsupported snapshot version %d", file, v)
+	}
+
+	// Configuration. Only the workspace settings are replaced;
+	// the rest, such as output and debugging, are kept.
+	conf := *p.context.Config()
+	conf.SetFloatPrec(uint(r.Uvarint()))
+	conf.SetMaxBits(uint(r.Uvarint()))
+	conf.SetMaxDigits(uint(r.Uvarint()))
+	conf.SetMaxStack(uint(r.Uvarint()))
+	origin := int(r.Varint())
+	if origin != 0 && origin != 1 {
+		p.errorf("restore: %s: corrupt snapshot", file)
+	}
+	conf.SetOrigin(origin)
+	conf.SetPrompt(r.String())
+	conf.SetFormat(r.String())
+	ibase, obase := int(r.Varint()), int(r.Varint())
+	conf.SetMachine(r.Uvarint() != 0)
+	conf.SetGlyphs(r.Uvarint() != 0)
+	conf.SetModulus(r.BigInt())
+	conf.SetRandomSeed(r.Varint())
+
+	// Ops, parsed in a scratch context as they were printed, in base 10.
+	ops := r.String()
+	scratchConf := conf
+	scratchConf.SetBase(10, 10)
+	scratchConf.SetModulus(nil)
+	scratchConf.SetOutput(io.Discard)
+	scratch := exec.NewContext(&scratchConf).(*exec.Context)
+	parser := NewParser(file, scan.New(scratch, file, bufio.NewReader(strings.NewReader(ops))), scratch)
+	if parser.runUntilError(file) != io.EOF {
+		p.errorf("restore: %s: cannot define ops", file)
+	}
+
+	// Global variables.
+	var syms []saveSym
+	for i, n := uint64(0), r.Uvarint(); i < n; i++ {
+		syms = append(syms, saveSym{r.String(), r.Value()})
+	}
+
+	// Now replace the workspace.
+	c := p.context
+	c.Clear()
+	conf.SetBase(ibase, obase)
+	*c.Config() = conf
+	for _, def := range scratch.Defs {
+		if def.IsBinary {
+			c.Define(scratch.BinaryFn[def.Name])
+		} else {
+			c.Define(scratch.UnaryFn[def.Name])
+		}
+	}
+	for _, sym := range syms {
+		c.AssignGlobal(sym.name, sym.val)
+	}
+	c.SetConstants()
+}
@@ -10,7 +10,9 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"math/big"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -23,6 +25,54 @@ import (
 
 const defaultFile = "save.ivy"
 
+// specialCommands lists the names of the special commands.
+// Keep it in step with the switch in special.
+var specialCommands = []string{
+	"base",
+	"checkpoint",
+	"clear",
+	"copy",
+	"cpu",
+	"debug",
+	"demo",
+	"diff",
+	"erase",
+	"export",
+	"format",
+	"get",
+	"glyphs",
+	"help",
+	"ibase",
+	"import",
+	"lib",
+	"log",
+	"machine",
+	"maxbits",
+	"maxdigits",
+	"maxstack",
+	"modulus",
+	"obase",
+	"op",
+	"origin",
+	"prec",
+	"prompt",
+	"restore",
+	"rollback",
+	"sandbox",
+	"save",
+	"seed",
+	"snapshot",
+	"test",
+	"undo",
+	"vars",
+}
+
+// SpecialCommands returns the names of the special commands, without
+// the leading parenthesis. It is used for completion when editing input.
+func SpecialCommands() []string {
+	return append([]string(nil), specialCommands...)
+}
+
 func (p *Parser) need(want ...scan.Type) scan.Token {
 	tok := p.next()
 	for _, w := range want {
@@ -51,14 +101,7 @@ func (p *Parser) nextDecimalNumber() int {
 // nextDecimalNumber64 returns the next number, which
 // must fit in a non-negative int64.
 func (p *Parser) nextDecimalNumber64() int64 {
-	conf := p.context.Config()
-	ibase, obase := conf.Base()
-	defer conf.SetBase(ibase, obase)
-	conf.SetBase(10, obase)
-	v, err := value.Parse(conf, p.need(scan.Number).Text)
-	if err != nil {
-		p.errorf("%s", err)
-	}
+	v := p.nextDecimalValue()
 	var n int64 = -1
 	switch num := v.(type) {
 	case value.Int:
@@ -77,6 +120,38 @@ func (p *Parser) nextDecimalNumber64() int64 {
 	return n
 }
 
+// nextDecimalBigInt returns the next number, which must be
+// a non-negative integer of any size.
+func (p *Parser) nextDecimalBigInt() *big.Int {
+	v := p.nextDecimalValue()
+	var n *big.Int
+	switch num := v.(type) {
+	case value.Int:
+		n = big.NewInt(int64(num))
+	case value.BigInt:
+		n = num.Int
+	default:
+		p.errorf("value must be an integer: %v", v)
+	}
+	if n.Sign() < 0 {
+		p.errorf("value must be a positive integer: %v", v)
+	}
+	return n
+}
+
+// nextDecimalValue returns the value of the next number, read in base 10.
+func (p *Parser) nextDecimalValue() value.Value {
+	conf := p.context.Config()
+	ibase, obase := conf.Base()
+	defer conf.SetBase(ibase, obase)
+	conf.SetBase(10, obase)
+	v, err := value.Parse(conf, p.need(scan.Number).Text)
+	if err != nil {
+		p.errorf("%s", err)
+	}
+	return v
+}
+
 func truth(x bool) int {
 	if x {
 		return 1
@@ -158,6 +233,31 @@ Switch:
 		case "obase":
 			obase = base
 		}
+	case "checkpoint":
+		if p.peek().Type == scan.EOF {
+			var names []string
+			for name := range p.context.Checkpoints {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				p.Println(name)
+			}
+			break Switch
+		}
+		// Record the base as the user set it.
+		conf.SetBase(ibase, obase)
+		p.context.Checkpoints[p.need(scan.Operator, scan.Identifier).Text] = p.context.Checkpoint()
+	case "clear":
+		p.context.Clear()
+	case "copy":
+		p.fileAccess("copy")
+		file := p.getString()
+		var names []string
+		for p.peek().Type != scan.EOF {
+			names = append(names, p.need(scan.Operator, scan.Identifier).Text)
+		}
+		p.copyFrom(file, names)
 	case "cpu":
 		p.Printf("%s\n", conf.PrintCPUTime())
 	case "debug":
@@ -184,6 +284,41 @@ Switch:
 		if !conf.SetDebug(name, number != 0) {
 			p.Println("no such debug flag:", name)
 		}
+	case "diff":
+		// With one checkpoint, compare it with the workspace.
+		from := p.checkpoint()
+		var to *exec.Checkpoint
+		if p.peek().Type == scan.EOF {
+			conf.SetBase(ibase, obase)
+			to = p.context.Checkpoint()
+		} else {
+			to = p.checkpoint()
+		}
+		for _, diff := range from.Diff(to) {
+			p.Println(diff)
+		}
+	case "erase":
+		if p.peek().Type == scan.Op {
+			p.next()
+			p.eraseOp()
+			break Switch
+		}
+		if p.peek().Type == scan.EOF {
+			p.errorf("nothing to erase")
+		}
+		for p.peek().Type != scan.EOF {
+			name := p.need(scan.Operator, scan.Identifier).Text
+			found := p.context.EraseVar(name)
+			if p.context.EraseOp(name, false) {
+				found = true
+			}
+			if p.context.EraseOp(name, true) {
+				found = true
+			}
+			if !found {
+				p.errorf("%q not defined", name)
+			}
+		}
 	case "demo":
 		p.need(scan.EOF)
 		if conf.Mobile() {
@@ -203,12 +338,74 @@ Switch:
 			break Switch
 		}
 		conf.SetFormat(p.getString())
+	case "glyphs":
+		if p.peek().Type == scan.EOF {
+			p.Println(truth(conf.Glyphs()))
+			break Switch
+		}
+		conf.SetGlyphs(p.nextDecimalNumber() != 0)
+	case "export":
+		p.fileAccess("export")
+		file := p.getString()
+		name, header, starts := p.csvNames()
+		p.exportCSV(file, name, header, starts)
 	case "get":
+		p.fileAccess("get")
 		if p.peek().Type == scan.EOF {
 			p.runFromFile(p.context, defaultFile)
 		} else {
 			p.runFromFile(p.context, p.getString())
 		}
+	case "import":
+		p.fileAccess("import")
+		file := p.getString()
+		name, header, starts := p.csvNames()
+		p.importCSV(file, name, header, starts)
+	case "lib":
+		if p.peek().Type == scan.EOF {
+			var names []string
+			for _, name := range p.context.Libraries {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				p.Println(name)
+			}
+			break Switch
+		}
+		tok := p.need(scan.Identifier, scan.String)
+		name := tok.Text
+		if tok.Type == scan.String {
+			name = value.ParseString(name)
+		}
+		p.fileAccess("lib")
+		p.loadLibrary(name)
+	case "log":
+		if p.peek().Type == scan.EOF {
+			if log := conf.Log(); log != nil {
+				p.Println(log.Name())
+			}
+			break Switch
+		}
+		if tok := p.peek(); tok.Type == scan.Identifier && tok.Text == "off" {
+			p.next()
+			p.closeLog()
+			break Switch
+		}
+		p.fileAccess("log")
+		file := p.getString()
+		p.closeLog()
+		log, err := config.CreateLog(file)
+		if err != nil {
+			p.errorf("log: %s", err)
+		}
+		conf.SetLog(log)
+	case "machine":
+		if p.peek().Type == scan.EOF {
+			p.Println(truth(conf.Machine()))
+			break Switch
+		}
+		conf.SetMachine(p.nextDecimalNumber() != 0)
 	case "maxbits":
 		if p.peek().Type == scan.EOF {
 			p.Printf("%d\n", conf.MaxBits())
@@ -230,6 +427,16 @@ Switch:
 		}
 		max := p.nextDecimalNumber()
 		conf.SetMaxStack(uint(max))
+	case "modulus":
+		if p.peek().Type == scan.EOF {
+			if m := conf.Modulus(); m != nil {
+				p.Printf("%s\n", m)
+			} else {
+				p.Printf("0\n")
+			}
+			break Switch
+		}
+		conf.SetModulus(p.nextDecimalBigInt())
 	case "op", "ops": // We keep forgetting whether it's a plural or not.
 		if p.peek().Type == scan.EOF {
 			var unary, binary []string
@@ -257,15 +464,16 @@ Switch:
 			break Switch
 		}
 		name := p.need(scan.Operator, scan.Identifier).Text
-		fn := p.context.UnaryFn[name]
 		found := false
-		if fn != nil {
-			p.Println(fn)
-			found = true
-		}
-		fn = p.context.BinaryFn[name]
-		if fn != nil {
-			p.Println(fn)
+		for _, fn := range []*exec.Function{p.context.UnaryFn[name], p.context.BinaryFn[name]} {
+			if fn == nil {
+				continue
+			}
+			if conf.Glyphs() {
+				p.Println(scan.Glyphs(fn.String()))
+			} else {
+				p.Println(fn)
+			}
 			found = true
 		}
 		if !found {
@@ -298,7 +506,30 @@ Switch:
 			break Switch
 		}
 		conf.SetPrompt(p.getString())
+	case "restore":
+		p.fileAccess("restore")
+		if p.peek().Type == scan.EOF {
+			p.restore(defaultSnapshot)
+		} else {
+			p.restore(p.getString())
+		}
+		// The snapshot sets the base.
+		ibase, obase = conf.Base()
+	case "rollback":
+		p.context.Rollback(p.checkpoint())
+		ibase, obase = conf.Base()
+	case "sandbox":
+		if p.peek().Type == scan.EOF {
+			p.Println(truth(conf.Sandbox()))
+			break Switch
+		}
+		sandbox := p.nextDecimalNumber() != 0
+		if conf.Sandbox() && !sandbox {
+			p.errorf("cannot leave the sandbox")
+		}
+		conf.SetSandbox(sandbox)
 	case "save":
+		p.fileAccess("save")
 		// Must restore ibase, obase for save.
 		conf.SetBase(ibase, obase)
 		if p.peek().Type == scan.EOF {
@@ -312,6 +543,40 @@ Switch:
 			break Switch
 		}
 		conf.SetRandomSeed(p.nextDecimalNumber64())
+	case "snapshot":
+		p.fileAccess("snapshot")
+		// Must restore ibase, obase for snapshot.
+		conf.SetBase(ibase, obase)
+		if p.peek().Type == scan.EOF {
+			snapshot(p.context, defaultSnapshot)
+		} else {
+			snapshot(p.context, p.getString())
+		}
+	case "test":
+		if p.peek().Type != scan.EOF {
+			p.fileAccess("test")
+			p.runFromFile(p.context, p.getString())
+		}
+		p.runTests()
+	case "undo":
+		if !p.context.Undo() {
+			p.errorf("nothing to undo")
+		}
+		ibase, obase = conf.Base()
+	case "vars":
+		for _, sym := range sortSyms(p.context.Globals) {
+			if value.IsConstant(sym.name) || sym.name == "_" || sym.name == "_error" {
+				continue
+			}
+			switch val := value.Boxed(sym.val).(type) {
+			case value.Vector:
+				p.Printf("%s\tvector %d\n", sym.name, len(val))
+			case *value.Matrix:
+				p.Printf("%s\tmatrix %s\n", sym.name, strings.Trim(fmt.Sprint(val.Shape()), "[]"))
+			default:
+				p.Printf("%s\t%s\n", sym.name, value.TypeName(val))
+			}
+		}
 	default:
 		p.errorf(")%s: not recognized", text)
 	}
@@ -323,24 +588,138 @@ Switch:
 	p.need(scan.EOF)
 }
 
+// eraseOp implements ")erase op", which removes user-defined ops but
+// never variables. Its operands are shaped like the start of an op
+// declaration: "f" removes both forms of op f, "f x" only its unary
+// form, and "x f y" only its binary form.
+func (p *Parser) eraseOp() {
+	var words []string
+	for p.peek().Type != scan.EOF {
+		words = append(words, p.need(scan.Operator, scan.Identifier).Text)
+	}
+	var name string
+	found := false
+	switch len(words) {
+	case 1:
+		name = words[0]
+		found = p.context.EraseOp(name, false)
+		if p.context.EraseOp(name, true) {
+			found = true
+		}
+	case 2:
+		name = words[0]
+		found = p.context.EraseOp(name, false)
+	case 3:
+		name = words[1]
+		found = p.context.EraseOp(name, true)
+	default:
+		p.errorf("usage: )erase op f, )erase op f x or )erase op x f y")
+	}
+	if !found {
+		p.errorf("op %q not defined", name)
+	}
+}
+
+// closeLog stops logging the session, if it is being logged.
+func (p *Parser) closeLog() {
+	conf := p.context.Config()
+	if log := conf.Log(); log != nil {
+		conf.SetLog(nil)
+		if err := log.Close(); err != nil {
+			p.errorf("log: %s", err)
+		}
+	}
+}
+
+// fileAccess fails if the sandbox forbids the special command to read or write files.
+func (p *Parser) fileAccess(cmd string) {
+	if p.context.Config().Sandbox() {
+		p.errorf("%s: file access not permitted in sandbox", cmd)
+	}
+}
+
+// checkpoint returns the checkpoint whose name must be next in the input.
+func (p *Parser) checkpoint() *exec.Checkpoint {
+	name := p.need(scan.Operator, scan.Identifier).Text
+	cp := p.context.Checkpoints[name]
+	if cp == nil {
+		p.errorf("no checkpoint %s", name)
+	}
+	return cp
+}
+
 // getString returns the value of the string that must be next in the input.
 func (p *Parser) getString() string {
 	return value.ParseString(p.need(scan.String).Text)
 }
 
+// csvNames returns the variable name, and the optional names for the
+// header and the field starts, that follow the file name in import and
+// export. An empty string in place of the header name means none.
+func (p *Parser) csvNames() (name, header, starts string) {
+	name = p.need(scan.Identifier).Text
+	if p.peek().Type != scan.EOF {
+		tok := p.need(scan.Identifier, scan.String)
+		header = tok.Text
+		if tok.Type == scan.String {
+			if value.ParseString(tok.Text) != "" {
+				p.errorf("header name must be an identifier or \"\"")
+			}
+			header = ""
+		}
+	}
+	if p.peek().Type != scan.EOF {
+		starts = p.need(scan.Identifier).Text
+	}
+	return name, header, starts
+}
+
 var runDepth = 0
 
 // runFromFile executes the contents of the named file.
+// A relative name not found in the current directory is looked up in $IVYPATH.
 func (p *Parser) runFromFile(context value.Context, name string) {
+	name = value.FindFile(name)
 	fd, err := os.Open(name)
 	if err != nil {
 		p.errorf("%s", err)
 	}
+	defer fd.Close()
 	p.runFromReader(context, name, fd, true)
 }
 
+// loadLibrary executes the named library, found as by FindFile, unless it
+// has already been loaded. A name without an extension has .ivy appended.
+func (p *Parser) loadLibrary(name string) {
+	file := name
+	if filepath.Ext(file) == "" {
+		file += ".ivy"
+	}
+	file = value.FindFile(file)
+	path, err := filepath.Abs(file)
+	if err != nil {
+		p.errorf("%s", err)
+	}
+	if _, ok := p.context.Libraries[path]; ok {
+		return
+	}
+	fd, err := os.Open(file)
+	if err != nil {
+		p.errorf("%s", err)
+	}
+	defer fd.Close()
+	// Record the library before running it, so a library that
+	// loads itself, directly or not, does not recur. Forget it if
+	// it fails, so a later )lib tries again.
+	p.context.Libraries[path] = name
+	if !p.runFromReader(p.context, file, fd, true) {
+		delete(p.context.Libraries, path)
+	}
+}
+
 // runFromReader executes the contents of the io.Reader, identified by name.
-func (p *Parser) runFromReader(context value.Context, name string, reader io.Reader, stopOnError bool) {
+// It reports whether it ran to the end without error.
+func (p *Parser) runFromReader(context value.Context, name string, reader io.Reader, stopOnError bool) (ok bool) {
 	runDepth++
 	if runDepth > 10 {
 		p.errorf("invocations of %q nested too deep", name)
@@ -359,11 +738,14 @@ func (p *Parser) runFromReader(context value.Context, name string, reader io.Rea
 	}()
 	scanner := scan.New(context, name, bufio.NewReader(reader))
 	parser := NewParser(name, scanner, p.context)
+	ok = true
 	for parser.runUntilError(name) != io.EOF {
+		ok = false
 		if stopOnError {
 			break
 		}
 	}
+	return ok
 }
 
 func (p *Parser) runUntilError(name string) error {
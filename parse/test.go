@@ -0,0 +1,54 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parse
+
+// Running tests written in ivy.
+
+import (
+	"strings"
+	"time"
+
+	"robpike.io/ivy/value"
+)
+
+// CPUTime reports user and system time. It is set by package run,
+// which holds the system-specific implementation.
+var CPUTime = func() (user, sys time.Duration) { return 0, 0 }
+
+// runTests runs, in the order they were defined, the unary ops whose names
+// begin with "test", each applied to an empty vector. A test passes if it
+// raises no error. It prints the result and time of each test, recording
+// the time with SetCPUTime, and then a summary, which is an error if any
+// test failed.
+func (p *Parser) runTests() {
+	conf := p.context.Config()
+	var tests []string
+	for _, def := range p.context.Defs {
+		if !def.IsBinary && strings.HasPrefix(def.Name, "test") {
+			tests = append(tests, def.Name)
+		}
+	}
+	if len(tests) == 0 {
+		p.errorf("no tests: no unary ops named test...")
+	}
+	failed := 0
+	for _, name := range tests {
+		start := time.Now()
+		user, sys := CPUTime()
+		_, err := try(p.context, &unary{op: name, right: value.NewIntVector([]int{})})
+		user2, sys2 := CPUTime()
+		conf.SetCPUTime(time.Since(start), user2-user, sys2-sys)
+		if err != nil {
+			failed++
+			p.Printf("FAIL\t%s\t%s\n\t%s\n", name, conf.PrintCPUTime(), err)
+			continue
+		}
+		p.Printf("ok\t%s\t%s\n", name, conf.PrintCPUTime())
+	}
+	if failed > 0 {
+		p.errorf("%d passed, %d failed", len(tests)-failed, failed)
+	}
+	p.Printf("%d passed, 0 failed\n", len(tests))
+}
@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"robpike.io/ivy/config"
+	"robpike.io/ivy/exec"
 	"robpike.io/ivy/parse"
 	"robpike.io/ivy/scan"
 	"robpike.io/ivy/value"
@@ -23,6 +24,7 @@ import (
 
 func init() {
 	value.IvyEval = IvyEval
+	parse.CPUTime = func() (user, sys time.Duration) { return cpuTime() }
 }
 
 // IvyEval is the function called by value/unaryIvy to implement the ivy (eval) operation.
@@ -49,7 +51,11 @@ var cpuTime = func() (user, sys time.Duration) { return 0, 0 }
 func Run(p *parse.Parser, context value.Context, interactive bool) (success bool) {
 	conf := context.Config()
 	writer := conf.Output()
+	// JSON output is read by programs, so it gets no prompts or blank lines.
+	layout := interactive && !conf.JSON()
+	untee := func() {} // Set by teeLog while a line executes.
 	defer func() {
+		defer untee()
 		if conf.Debug("panic") {
 			return
 		}
@@ -63,7 +69,7 @@ func Run(p *parse.Parser, context value.Context, interactive bool) (success bool
 		}
 		if ok {
 			fmt.Fprintf(conf.ErrOutput(), "%s%s\n", p.Loc(), err)
-			if interactive {
+			if layout {
 				fmt.Fprintln(writer)
 			}
 			success = false
@@ -72,9 +78,13 @@ func Run(p *parse.Parser, context value.Context, interactive bool) (success bool
 		panic(err)
 	}()
 	for {
-		if interactive {
+		if layout {
 			fmt.Fprint(writer, conf.Prompt())
 		}
+		if c, ok := context.(*exec.Context); ok {
+			c.SaveUndo() // For )undo.
+		}
+		untee = teeLog(conf)
 		exprs, ok := p.Line()
 		var values []value.Value
 		if exprs != nil {
@@ -88,9 +98,11 @@ func Run(p *parse.Parser, context value.Context, interactive bool) (success bool
 				values = context.Eval(exprs)
 			}
 		}
-		if printValues(conf, writer, values) {
+		if printValues(conf, conf.Output(), values) {
 			context.AssignGlobal("_", values[len(values)-1])
 		}
+		untee()
+		untee = func() {}
 		if !ok {
 			return true
 		}
@@ -100,11 +112,30 @@ func Run(p *parse.Parser, context value.Context, interactive bool) (success bool
 					fmt.Printf("(%s)\n", conf.PrintCPUTime())
 				}
 			}
-			fmt.Fprintln(writer)
+			if layout {
+				fmt.Fprintln(writer)
+			}
 		}
 	}
 }
 
+// teeLog arranges that, if a log of the session is being kept, output
+// and error output are also written to the log. It returns a function
+// that restores the original outputs.
+func teeLog(conf *config.Config) func() {
+	log := conf.Log()
+	if log == nil {
+		return func() {}
+	}
+	out, errOut := conf.Output(), conf.ErrOutput()
+	conf.SetOutput(io.MultiWriter(out, log.Output()))
+	conf.SetErrOutput(io.MultiWriter(errOut, log.ErrOutput()))
+	return func() {
+		conf.SetOutput(out)
+		conf.SetErrOutput(errOut)
+	}
+}
+
 // eval runs until EOF or error. It prints every value but the last, and returns the last.
 // By last we mean the last expression of the last evaluation.
 // (Expressions are separated by ; in the input.)
@@ -148,6 +179,9 @@ func printValues(conf *config.Config, writer io.Writer, values []value.Value) bo
 		}
 		fmt.Fprintln(writer)
 	}
+	if conf.JSON() {
+		return printJSON(writer, values)
+	}
 	printed := false
 	for _, v := range values {
 		if _, ok := v.(parse.Assignment); ok {
@@ -166,12 +200,43 @@ func printValues(conf *config.Config, writer io.Writer, values []value.Value) bo
 	return printed
 }
 
+// printJSON prints each of the values as a line of JSON.
+// The return value reports whether it printed anything.
+func printJSON(writer io.Writer, values []value.Value) bool {
+	printed := false
+	for _, v := range values {
+		if _, ok := v.(parse.Assignment); ok {
+			continue
+		}
+		data, err := value.MarshalJSON(v)
+		if err != nil {
+			value.Errorf("%s", err)
+		}
+		fmt.Fprintf(writer, "%s\n", data)
+		printed = true
+	}
+	return printed
+}
+
 // Ivy evaluates the input string, appending standard output
 // and error output to the provided buffers, which it does by
 // calling context.Config.SetOutput and SetError.
 // If execution caused errors, they will be returned concatenated
 // together in the error value returned.
+// The exit operator stops evaluation; a non-zero status is
+// reported as an error.
 func Ivy(context value.Context, expr string, stdout, stderr *bytes.Buffer) {
+	defer func() {
+		if err := recover(); err != nil {
+			status, ok := err.(value.Exit)
+			if !ok {
+				panic(err)
+			}
+			if status != 0 {
+				fmt.Fprintf(stderr, "%s\n", status)
+			}
+		}
+	}()
 	if !strings.HasSuffix(expr, "\n") {
 		expr += "\n"
 	}
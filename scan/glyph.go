@@ -0,0 +1,228 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+// APL glyphs, accepted as aliases for the names of ivy's operators.
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// glyph records the ivy operators spelled by an APL glyph.
+// APL uses some glyphs, such as ⌈, for both a unary and a
+// binary operator that have different names in ivy; for those,
+// the parser resolves the glyph once it knows how it is used.
+// An empty name means the glyph has no ivy equivalent in that role.
+type glyph struct {
+	unary  string
+	binary string
+}
+
+// glyphs maps APL glyphs to ivy operators. The correspondence
+// follows the tables in the ivy documentation.
+var glyphs = map[rune]glyph{
+	'⌈': {"ceil", "max"},
+	'⌊': {"floor", "min"},
+	'×': {"sgn", "*"},
+	'∣': {"abs", ""},
+	'÷': {"/", "/"},
+	'⋆': {"**", "**"},
+	'−': {"-", "-"},
+	'⍴': {"rho", "rho"},
+	'⍳': {"iota", "iota"},
+	'⍟': {"log", "log"},
+	'⌽': {"rot", "rot"},
+	'⊖': {"flip", "flip"},
+	'⍉': {"transp", "transp"},
+	'⍕': {"text", "text"},
+	'∼': {"not", ""},
+	'⍋': {"up", ""},
+	'⍒': {"down", ""},
+	'⍎': {"ivy", ""},
+	'∈': {"", "in"},
+	'↑': {"", "take"},
+	'↓': {"", "drop"},
+	'⊥': {"", "decode"},
+	'⊤': {"", "encode"},
+	'≤': {"", "<="},
+	'≥': {"", ">="},
+	'≠': {"", "!="},
+	'∨': {"", "or"},
+	'∧': {"", "and"},
+	'⍱': {"", "nor"},
+	'⍲': {"", "nand"},
+	'∘': {"", "o"}, // Only in outer product, ∘.
+}
+
+// aplGlyph maps ivy operator names back to APL glyphs, for display.
+var aplGlyph = make(map[string]rune)
+
+func init() {
+	for r, g := range glyphs {
+		if r == '−' || r == '∘' {
+			// Ivy's minus is APL's; "o" alone is not an operator.
+			continue
+		}
+		if g.unary != "" {
+			aplGlyph[g.unary] = r
+		}
+		if g.binary != "" {
+			aplGlyph[g.binary] = r
+		}
+	}
+}
+
+// isGlyph reports whether r is an APL glyph standing for an operator.
+func isGlyph(r rune) bool {
+	_, ok := glyphs[r]
+	return ok
+}
+
+// UnaryName returns the name of the unary operator spelled by the
+// operator token text, which may be an APL glyph. If text is a glyph
+// with no unary form in ivy, such as ∈, it returns text and false.
+func UnaryName(text string) (string, bool) {
+	g, ok := singleGlyph(text)
+	if !ok {
+		return text, true
+	}
+	if g.unary == "" {
+		return text, false
+	}
+	return g.unary, true
+}
+
+// BinaryName returns the name of the binary operator spelled by the
+// operator token text, which may be an APL glyph. If text is a glyph
+// with no binary form in ivy, such as ∣, it returns text and false.
+// (APL's binary ∣ is residue, but with its operands in the opposite
+// order to ivy's mod, so it is not an alias for it.)
+func BinaryName(text string) (string, bool) {
+	g, ok := singleGlyph(text)
+	if !ok {
+		return text, true
+	}
+	if g.binary == "" {
+		return text, false
+	}
+	return g.binary, true
+}
+
+// singleGlyph returns the glyph entry if text is a single APL glyph.
+func singleGlyph(text string) (glyph, bool) {
+	r, size := utf8.DecodeRuneInString(text)
+	if size != len(text) {
+		return glyph{}, false
+	}
+	g, ok := glyphs[r]
+	return g, ok
+}
+
+// canonical returns the ivy spelling of the operator text. A bare glyph
+// whose meaning depends on whether it is used as a unary or binary operator,
+// or that has only one of those forms, is left alone for the parser to
+// resolve. Inside a reduction, scan, or product, every glyph denotes a
+// binary operator.
+func canonical(text string) string {
+	if g, ok := singleGlyph(text); ok {
+		if g.unary == g.binary {
+			return g.unary
+		}
+		return text
+	}
+	if strings.IndexFunc(text, isGlyph) < 0 {
+		return text
+	}
+	var b strings.Builder
+	for _, r := range text {
+		if g, ok := glyphs[r]; ok && g.binary != "" {
+			b.WriteString(g.binary)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Glyphs returns the program text, as produced by a ProgString method,
+// with the names of built-in operators replaced by their APL glyphs and
+// the signs of negative numbers written as ¯. The result is for display;
+// ivy reads it back, but == and the ivy-only operators keep their names.
+func Glyphs(text string) string {
+	var b strings.Builder
+	for len(text) > 0 {
+		r, size := utf8.DecodeRuneInString(text)
+		switch {
+		case r == '\'' || r == '"' || r == '`':
+			// Copy a quoted string through unchanged.
+			end := size
+			for end < len(text) {
+				c, w := utf8.DecodeRuneInString(text[end:])
+				end += w
+				if c == '\\' && r != '`' && end < len(text) {
+					_, w = utf8.DecodeRuneInString(text[end:])
+					end += w
+					continue
+				}
+				if c == r {
+					break
+				}
+			}
+			b.WriteString(text[:end])
+			text = text[end:]
+			continue
+		case strings.ContainsRune(" \t\n()[];", r):
+			b.WriteRune(r)
+		case isDigit(r) || r == '.':
+			// A number. Mark negative exponents APL-style.
+			end := strings.IndexAny(text, " \t\n()[];")
+			if end < 0 {
+				end = len(text)
+			}
+			b.WriteString(strings.Replace(text[:end], "-", "¯", -1))
+			size = end
+		case r == '-' && len(text) > 1 && (isDigit(rune(text[1])) || text[1] == '.'):
+			b.WriteRune('¯')
+		default:
+			// An operator or identifier: take the rest of the word.
+			end := strings.IndexAny(text, " \t\n()[];")
+			if end < 0 {
+				end = len(text)
+			}
+			b.WriteString(glyphWord(text[:end]))
+			size = end
+		}
+		text = text[size:]
+	}
+	return b.String()
+}
+
+// glyphWord returns the APL spelling of a word from program text,
+// which may be an operator, a reduction or scan, or a product.
+func glyphWord(word string) string {
+	if g, ok := aplGlyph[word]; ok {
+		return string(g)
+	}
+	if n := len(word); n > 1 && (word[n-1] == '/' || word[n-1] == '\\') {
+		if g, ok := aplGlyph[word[:n-1]]; ok {
+			return string(g) + word[n-1:]
+		}
+		return word
+	}
+	if dot := strings.IndexByte(word, '.'); dot > 0 && dot < len(word)-1 {
+		left, right := word[:dot], word[dot+1:]
+		if left == "o" {
+			left = "∘"
+		} else if g, ok := aplGlyph[left]; ok {
+			left = string(g)
+		}
+		if g, ok := aplGlyph[right]; ok {
+			right = string(g)
+		}
+		return left + "." + right
+	}
+	return word
+}
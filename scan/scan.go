@@ -48,6 +48,8 @@ const (
 	Space      // run of spaces separating
 	String     // quoted string (includes quotes)
 	Colon      // ':'
+	Catch      // "catch", error trapping keyword
+	Assert     // "assert", assertion keyword
 )
 
 func (i Token) String() string {
@@ -150,6 +152,12 @@ func (l *Scanner) emit(t Type) {
 		l.line++
 	}
 	s := l.input[l.start:l.pos]
+	switch t {
+	case Number, Rational:
+		s = strings.Replace(s, "¯", "-", -1)
+	case Operator:
+		s = canonical(s)
+	}
 	config := l.context.Config()
 	if config.Debug("tokens") {
 		fmt.Fprintf(config.Output(), "%s:%d: emit %s\n", l.name, l.line, Token{t, l.line, s})
@@ -281,7 +289,7 @@ func lexAny(l *Scanner) stateFn {
 			}
 		}
 		fallthrough
-	case r == '.' || '0' <= r && r <= '9':
+	case r == '.' || r == '¯' || '0' <= r && r <= '9':
 		l.backup()
 		return lexNumber
 	case r == '=':
@@ -354,7 +362,17 @@ Loop:
 				return lexOperator
 			case word == "op":
 				l.emit(Op)
+			case word == "catch":
+				l.emit(Catch)
+			case word == "assert":
+				l.emit(Assert)
 			case isAllDigits(word, l.context.Config().InputBase()):
+				// The number may have a fraction, as in a.8 in base 16.
+				digits := digitsForBase(l.context.Config().InputBase())
+				if rest := l.input[l.pos:]; len(rest) > 1 && rest[0] == '.' && strings.IndexByte(digits, rest[1]) >= 0 {
+					l.next()
+					l.acceptRun(digits)
+				}
 				l.emit(Number)
 			default:
 				l.emit(Identifier)
@@ -369,7 +387,7 @@ Loop:
 // whatever; there may be a reduction or inner or outer product.
 func lexOperator(l *Scanner) stateFn {
 	// It might be an inner product or reduction, but only if it is a binary operator.
-	word := l.input[l.start:l.pos]
+	word, _ := BinaryName(canonical(l.input[l.start:l.pos]))
 	if word == "o" || value.BinaryOps[word] != nil || l.context.UserDefined(word, true) {
 		switch l.peek() {
 		case '/':
@@ -453,8 +471,12 @@ Loop:
 // and "089" - but when it's wrong the input is invalid and the parser (via
 // strconv) will notice.
 func lexNumber(l *Scanner) stateFn {
-	// Optional leading sign.
-	if l.accept("-") {
+	// Optional leading sign. APL's high minus is always a sign.
+	if l.accept("¯") {
+		if r := l.peek(); r != '.' && !l.isNumeral(r) {
+			return l.errorf("bad number syntax: %s", l.input[l.start:l.pos])
+		}
+	} else if l.accept("-") {
 		// Might not be a number.
 		r := l.peek()
 		// Might be a scan or reduction.
@@ -475,7 +497,7 @@ func lexNumber(l *Scanner) stateFn {
 	if unicode.ToLower(r) == 'j' {
 		l.emit(Number)
 		l.accept("jJ")
-		if r := l.peek(); r != '.' && r != '-' && !l.isNumeral(r) {
+		if r := l.peek(); r != '.' && r != '-' && r != '¯' && !l.isNumeral(r) {
 			return l.errorf("bad complex number syntax: %s", l.input[l.start:l.pos+1])
 		}
 		l.emit(Imaginary)
@@ -531,7 +553,7 @@ func (l *Scanner) scanNumber(followingSlashOK, followingComplexOK bool) bool {
 		l.acceptRun(digits)
 	}
 	if l.accept("eE") {
-		l.accept("+-")
+		l.accept("+-¯")
 		l.acceptRun("0123456789")
 	}
 	r := l.peek()
@@ -698,9 +720,12 @@ func isAllDigits(s string, base int) bool {
 }
 
 // isOperator reports whether r is an operator. It may advance the lexer one character
-// if it is a two-character operator.
+// if it is a two-character operator. APL glyphs are operators too; ∘ only as
+// the start of an outer product.
 func (l *Scanner) isOperator(r rune) bool {
 	switch r {
+	case '∘':
+		return l.peek() == '.'
 	case '?', '+', '-', '/', '%', '&', '|', '^', ',':
 		// No follow-on possible.
 	case '!':
@@ -728,7 +753,7 @@ func (l *Scanner) isOperator(r rune) bool {
 		}
 		l.next()
 	default:
-		return false
+		return isGlyph(r)
 	}
 	return true
 }
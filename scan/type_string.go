@@ -27,11 +27,13 @@ func _() {
 	_ = x[Space-16]
 	_ = x[String-17]
 	_ = x[Colon-18]
+	_ = x[Catch-19]
+	_ = x[Assert-20]
 }
 
-const _Type_name = "EOFErrorNewlineAssignCharIdentifierImaginaryLeftBrackLeftParenNumberOperatorOpRationalRightBrackRightParenSemicolonSpaceStringColon"
+const _Type_name = "EOFErrorNewlineAssignCharIdentifierImaginaryLeftBrackLeftParenNumberOperatorOpRationalRightBrackRightParenSemicolonSpaceStringColonCatchAssert"
 
-var _Type_index = [...]uint8{0, 3, 8, 15, 21, 25, 35, 44, 53, 62, 68, 76, 78, 86, 96, 106, 115, 120, 126, 131}
+var _Type_index = [...]uint8{0, 3, 8, 15, 21, 25, 35, 44, 53, 62, 68, 76, 78, 86, 96, 106, 115, 120, 126, 131, 136, 142}
 
 func (i Type) String() string {
 	if i < 0 || i >= Type(len(_Type_index)-1) {
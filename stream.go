@@ -0,0 +1,109 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+// Stream mode: processing standard input a line at a time, like awk.
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"robpike.io/ivy/config"
+	"robpike.io/ivy/value"
+)
+
+// runStream executes begin, then each once per line of the input with x
+// set to the line and nr to its number, and finally end. Empty strings
+// are skipped. It returns false if any execution failed.
+func runStream(context value.Context, r io.Reader, begin, each, end string) bool {
+	if begin != "" && !runString(context, begin) {
+		return false
+	}
+	if each != "" {
+		conf := context.Config()
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, 1<<30)
+		for nr := 1; scanner.Scan(); nr++ {
+			context.AssignGlobal("nr", value.Int(nr))
+			context.AssignGlobal("x", lineValue(conf, scanner.Text()))
+			if !runString(context, each) {
+				return false
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			fmt.Fprintf(os.Stderr, "ivy: %s\n", err)
+			return false
+		}
+	}
+	return end == "" || runString(context, end)
+}
+
+// lineValue returns the value of a line of input: the numbers in it,
+// separated by white space, as a scalar or vector or, if it is not all
+// numbers, its text as a char vector.
+func lineValue(conf *config.Config, line string) value.Value {
+	if nums, ok := numbers(conf, line); ok {
+		if len(nums) == 1 {
+			return nums[0]
+		}
+		return value.NewVector(nums)
+	}
+	return value.NewCharVector(line)
+}
+
+// allValue returns the value of the text of all the input as a matrix
+// with one row per line. If every line holds the same number of numbers,
+// they are the elements, and if that number is one the result is a vector.
+// Otherwise the result is a char matrix of the lines, padded with blanks.
+func allValue(conf *config.Config, text string) value.Value {
+	text = strings.TrimSuffix(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if text == "" {
+		return value.NewVector(nil)
+	}
+	lines := strings.Split(text, "\n")
+	var elems []value.Value
+	width := -1
+	for _, line := range lines {
+		nums, ok := numbers(conf, line)
+		if !ok || (width >= 0 && len(nums) != width) {
+			return value.NewCharMatrix(lines)
+		}
+		width = len(nums)
+		elems = append(elems, nums...)
+	}
+	if width == 1 {
+		return value.NewVector(elems)
+	}
+	return value.NewMatrix([]int{len(lines), width}, elems)
+}
+
+// numbers returns the numbers in the line, separated by white space,
+// parsed as ivy parses numbers. It reports whether the line is not
+// empty and holds only numbers.
+func numbers(conf *config.Config, line string) (nums []value.Value, ok bool) {
+	defer func() {
+		if err := recover(); err != nil {
+			if _, isErr := err.(value.Error); !isErr {
+				panic(err)
+			}
+			nums, ok = nil, false
+		}
+	}()
+	for _, field := range strings.Fields(line) {
+		if strings.Count(field, "/") > 1 {
+			// Parse expects at most one slash, as the scanner guarantees.
+			return nil, false
+		}
+		v, err := value.Parse(conf, field)
+		if err != nil {
+			return nil, false
+		}
+		nums = append(nums, v)
+	}
+	return nums, len(nums) > 0
+}
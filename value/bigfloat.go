@@ -6,7 +6,9 @@ package value
 
 import (
 	"fmt"
+	"math"
 	"math/big"
+	"strings"
 
 	"robpike.io/ivy/config"
 )
@@ -49,6 +51,9 @@ func (f BigFloat) Sprint(conf *config.Config) string {
 			verb, prec = v, p
 		}
 	}
+	if base := conf.OutputBase(); format == "" && base != 0 && base != 10 {
+		return baseString(f.Float, base, baseDigits(prec, base))
+	}
 	// Printing huge floats can be very slow using
 	// big.Float's native methods; see issue #11068.
 	// For example 1e5000000 takes a minute of CPU time just
@@ -139,6 +144,8 @@ func (f BigFloat) toType(op string, conf *config.Config, which valueType) Value
 	switch which {
 	case bigFloatType:
 		return f
+	case intervalType:
+		return floatInterval(conf, f.Float)
 	case complexType:
 		return newComplexReal(f)
 	case vectorType:
@@ -159,3 +166,77 @@ func (f BigFloat) shrink() Value {
 	}
 	return f
 }
+
+// baseDigits returns the number of digits in the base that give
+// at least the precision of prec decimal digits.
+func baseDigits(prec, base int) int {
+	return int(math.Ceil(float64(prec) * math.Log(10) / math.Log(float64(base))))
+}
+
+// baseString formats x in the base with the specified number of significant
+// digits, correctly rounded (to even). Like %g, it uses positional notation
+// for moderate exponents; otherwise it prints d.ddd@e, meaning d.ddd times
+// base**e, with e in decimal.
+func baseString(x *big.Float, base, digits int) string {
+	if x.Sign() == 0 || x.IsInf() {
+		return x.Text('g', 1)
+	}
+	sign := ""
+	if x.Sign() < 0 {
+		sign = "-"
+	}
+	r, _ := new(big.Float).Abs(x).Rat(nil)
+	b := big.NewInt(int64(base))
+	// Find e such that base**e <= r < base**(e+1), starting from an estimate.
+	e := int(math.Floor(float64(x.MantExp(nil)-1) / math.Log2(float64(base))))
+	for r.Cmp(ratPow(b, e+1)) >= 0 {
+		e++
+	}
+	for r.Cmp(ratPow(b, e)) < 0 {
+		e--
+	}
+	// Scale r to an integer of the right number of digits, rounding to even.
+	// Rounding up may add a digit, in which case we go around again.
+	limit := new(big.Int).Exp(b, big.NewInt(int64(digits)), nil)
+	var n *big.Int
+	for {
+		s := new(big.Rat).Mul(r, ratPow(b, digits-1-e))
+		var rem big.Int
+		n, _ = new(big.Int).QuoRem(s.Num(), s.Denom(), &rem)
+		switch rem.Lsh(&rem, 1).Cmp(s.Denom()) {
+		case 1:
+			n.Add(n, bigOne.Int)
+		case 0:
+			if n.Bit(0) == 1 {
+				n.Add(n, bigOne.Int)
+			}
+		}
+		if n.Cmp(limit) < 0 {
+			break
+		}
+		e++
+	}
+	str := strings.TrimRight(n.Text(base), "0")
+	switch {
+	case e < -4 || e >= digits:
+		mant := str[:1]
+		if len(str) > 1 {
+			mant += "." + str[1:]
+		}
+		return fmt.Sprintf("%s%s@%d", sign, mant, e)
+	case e < 0:
+		return sign + "0." + strings.Repeat("0", -e-1) + str
+	case len(str) <= e+1:
+		return sign + str + strings.Repeat("0", e+1-len(str))
+	}
+	return sign + str[:e+1] + "." + str[e+1:]
+}
+
+// ratPow returns b**e as a rational.
+func ratPow(b *big.Int, e int) *big.Rat {
+	p := new(big.Int).Exp(b, big.NewInt(int64(abs(e))), nil)
+	if e < 0 {
+		return new(big.Rat).SetFrac(bigOne.Int, p)
+	}
+	return new(big.Rat).SetInt(p)
+}
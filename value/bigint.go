@@ -50,6 +50,9 @@ func (i BigInt) Sprint(conf *config.Config) string {
 		return BigFloat{newF(conf).SetInt(i.Int)}.Sprint(conf)
 	}
 	if format != "" {
+		if verb := conf.ExactFormat(); verb != 0 {
+			return exactFormat(conf, format, verb, new(big.Rat).SetInt(i.Int), true)
+		}
 		verb, prec, ok := conf.FloatFormat()
 		if ok {
 			return i.floatString(verb, prec)
@@ -60,18 +63,11 @@ func (i BigInt) Sprint(conf *config.Config) string {
 	if i.BitLen() < intBits {
 		return Int(i.Int64()).Sprint(conf)
 	}
-	switch conf.OutputBase() {
-	case 0, 10:
-		return fmt.Sprintf("%d", i.Int)
-	case 2:
-		return fmt.Sprintf("%b", i.Int)
-	case 8:
-		return fmt.Sprintf("%o", i.Int)
-	case 16:
-		return fmt.Sprintf("%x", i.Int)
+	base := conf.OutputBase()
+	if base == 0 {
+		base = 10
 	}
-	Errorf("can't print number in base %d (yet)", conf.OutputBase())
-	return ""
+	return i.Text(base)
 }
 
 func (i BigInt) ProgString() string {
@@ -151,9 +147,16 @@ func (i BigInt) toType(op string, conf *config.Config, which valueType) Value {
 	case bigRatType:
 		r := big.NewRat(0, 1).SetInt(i.Int)
 		return BigRat{r}
+	case floatType:
+		f, _ := new(big.Float).SetInt(i.Int).Float64()
+		return checkFloat(f)
 	case bigFloatType:
 		f := new(big.Float).SetPrec(conf.FloatPrec()).SetInt(i.Int)
 		return BigFloat{f}
+	case intervalType:
+		return ratInterval(conf, new(big.Rat).SetInt(i.Int))
+	case complex128Type:
+		return Complex128(complex(float64(i.toType(op, conf, floatType).(Float)), 0))
 	case complexType:
 		return newComplexReal(i)
 	case vectorType:
@@ -19,21 +19,87 @@ type BigRat struct {
 
 // The input is known to be in floating-point syntax.
 // If there's a slash, the parsing is done in Parse().
-func setBigRatFromFloatString(_ *config.Config, s string) (br BigRat, err error) {
+func setBigRatFromFloatString(conf *config.Config, s string) (br BigRat, err error) {
 	// Be safe: Verify that it is floating-point, because otherwise
 	// we need to honor ibase.
 	if !strings.ContainsAny(s, ".eE") {
 		// Most likely a number like "08".
 		Errorf("bad number syntax: %s", s)
 	}
-	var ok bool
-	r, ok := big.NewRat(0, 1).SetString(s)
+	base := conf.InputBase()
+	if base == 0 {
+		base = 10
+	}
+	if base == 10 && !strings.ContainsRune(s, '(') {
+		r, ok := big.NewRat(0, 1).SetString(s)
+		if !ok {
+			return BigRat{}, errors.New("floating-point number syntax")
+		}
+		return BigRat{r}, nil
+	}
+	// The exponent, if any, is a decimal power of ten. Above base 14,
+	// e is a digit and there can be no exponent.
+	mant, exp := s, ""
+	if base <= 14 {
+		if i := strings.IndexAny(s, "eE"); i >= 0 {
+			mant, exp = s[:i], s[i+1:]
+		}
+	}
+	r, ok := baseRat(mant, base)
 	if !ok {
 		return BigRat{}, errors.New("floating-point number syntax")
 	}
+	if exp != "" {
+		e, ok := new(big.Rat).SetString("1e" + exp)
+		if !ok {
+			return BigRat{}, errors.New("floating-point number syntax")
+		}
+		r.Mul(r, e)
+	}
 	return BigRat{r}, nil
 }
 
+// baseRat returns the value of s, a number in the base with an optional
+// sign, point, and parenthesized repeating part after the point, as in
+// 0.1(6) for 1/6 in base 10.
+func baseRat(s string, base int) (*big.Rat, bool) {
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimLeft(s, "+-")
+	rep := ""
+	if open := strings.IndexByte(s, '('); open >= 0 {
+		if !strings.HasSuffix(s, ")") || !strings.Contains(s[:open], ".") || open+2 >= len(s) {
+			return nil, false
+		}
+		s, rep = s[:open], s[open+1:len(s)-1]
+	}
+	whole, frac := s, ""
+	if point := strings.IndexByte(s, '.'); point >= 0 {
+		whole, frac = s[:point], s[point+1:]
+	}
+	n, ok := new(big.Int).SetString(whole+frac, base)
+	if !ok {
+		return nil, false
+	}
+	b := big.NewInt(int64(base))
+	shift := new(big.Int).Exp(b, big.NewInt(int64(len(frac))), nil)
+	r := new(big.Rat).SetFrac(n, shift)
+	if rep != "" {
+		// The repeating digits d form the fraction d/(base**len(d) - 1),
+		// shifted by the digits after the point.
+		m, ok := new(big.Int).SetString(rep, base)
+		if !ok {
+			return nil, false
+		}
+		den := new(big.Int).Exp(b, big.NewInt(int64(len(rep))), nil)
+		den.Sub(den, bigOne.Int)
+		r.Add(r, new(big.Rat).SetFrac(m, den.Mul(den, shift)))
+	}
+	if neg {
+		r.Neg(r)
+	}
+	return r, true
+}
+
 func (r BigRat) String() string {
 	return "(" + r.Sprint(debugConf) + ")"
 }
@@ -45,6 +111,9 @@ func (r BigRat) Rank() int {
 func (r BigRat) Sprint(conf *config.Config) string {
 	format := conf.Format()
 	if format != "" {
+		if verb := conf.ExactFormat(); verb != 0 {
+			return exactFormat(conf, format, verb, r.Rat, true)
+		}
 		verb, prec, ok := conf.FloatFormat()
 		if ok {
 			return r.floatString(verb, prec)
@@ -178,9 +247,16 @@ func (r BigRat) toType(op string, conf *config.Config, which valueType) Value {
 	switch which {
 	case bigRatType:
 		return r
+	case floatType:
+		f, _ := r.Float64()
+		return checkFloat(f)
 	case bigFloatType:
 		f := new(big.Float).SetPrec(conf.FloatPrec()).SetRat(r.Rat)
 		return BigFloat{f}
+	case intervalType:
+		return ratInterval(conf, r.Rat)
+	case complex128Type:
+		return Complex128(complex(float64(r.toType(op, conf, floatType).(Float)), 0))
 	case complexType:
 		return newComplexReal(r)
 	case vectorType:
@@ -7,6 +7,7 @@ package value
 import (
 	"math"
 	"math/big"
+	"math/cmplx"
 	"sort"
 )
 
@@ -46,6 +47,17 @@ func rationalType(t1, t2 valueType) (valueType, valueType) {
 	return binaryArithType(t1, t2)
 }
 
+// intervalPromoteType promotes scalar arguments to intervals.
+func intervalPromoteType(t1, t2 valueType) (valueType, valueType) {
+	if t1 < intervalType {
+		t1 = intervalType
+	}
+	if t2 < intervalType {
+		t2 = intervalType
+	}
+	return binaryArithType(t1, t2)
+}
+
 // atLeastVectorType promotes both arguments to at least vectors.
 func atLeastVectorType(t1, t2 valueType) (valueType, valueType) {
 	if t1 < matrixType && t2 < matrixType {
@@ -172,8 +184,14 @@ func toBool(t Value) bool {
 		return t.Sign() != 0
 	case BigRat:
 		return t.Sign() != 0
+	case Float:
+		return t != 0
 	case BigFloat:
 		return t.Sign() != 0
+	case Interval:
+		return t.lo.Sign() != 0 || t.hi.Sign() != 0
+	case Complex128:
+		return t != 0
 	case Complex:
 		return toBool(t.real) || toBool(t.imag)
 	}
@@ -229,9 +247,18 @@ func init() {
 				bigRatType: func(c Context, u, v Value) Value {
 					return binaryBigRatOp(u, (*big.Rat).Add, v)
 				},
+				floatType: func(c Context, u, v Value) Value {
+					return binaryFloatOp(u, func(x, y float64) float64 { return x + y }, v)
+				},
 				bigFloatType: func(c Context, u, v Value) Value {
 					return binaryBigFloatOp(c, u, (*big.Float).Add, v)
 				},
+				intervalType: func(c Context, u, v Value) Value {
+					return intervalAdd(c, u.(Interval), v.(Interval))
+				},
+				complex128Type: func(c Context, u, v Value) Value {
+					return binaryComplex128Op(c, u, "+", func(x, y complex128) complex128 { return x + y }, v)
+				},
 				complexType: func(c Context, u, v Value) Value {
 					return binaryComplexOp(c, u, (Complex).Add, v)
 				},
@@ -254,9 +281,18 @@ func init() {
 				bigRatType: func(c Context, u, v Value) Value {
 					return binaryBigRatOp(u, (*big.Rat).Sub, v)
 				},
+				floatType: func(c Context, u, v Value) Value {
+					return binaryFloatOp(u, func(x, y float64) float64 { return x - y }, v)
+				},
 				bigFloatType: func(c Context, u, v Value) Value {
 					return binaryBigFloatOp(c, u, (*big.Float).Sub, v)
 				},
+				intervalType: func(c Context, u, v Value) Value {
+					return intervalSub(c, u.(Interval), v.(Interval))
+				},
+				complex128Type: func(c Context, u, v Value) Value {
+					return binaryComplex128Op(c, u, "-", func(x, y complex128) complex128 { return x - y }, v)
+				},
 				complexType: func(c Context, u, v Value) Value {
 					return binaryComplexOp(c, u, (Complex).Sub, v)
 				},
@@ -278,9 +314,18 @@ func init() {
 				bigRatType: func(c Context, u, v Value) Value {
 					return binaryBigRatOp(u, (*big.Rat).Mul, v)
 				},
+				floatType: func(c Context, u, v Value) Value {
+					return binaryFloatOp(u, func(x, y float64) float64 { return x * y }, v)
+				},
 				bigFloatType: func(c Context, u, v Value) Value {
 					return binaryBigFloatOp(c, u, (*big.Float).Mul, v)
 				},
+				intervalType: func(c Context, u, v Value) Value {
+					return intervalMul(c, u.(Interval), v.(Interval))
+				},
+				complex128Type: func(c Context, u, v Value) Value {
+					return binaryComplex128Op(c, u, "*", func(x, y complex128) complex128 { return x * y }, v)
+				},
 				complexType: func(c Context, u, v Value) Value {
 					return binaryComplexOp(c, u, (Complex).Mul, v)
 				},
@@ -298,9 +343,21 @@ func init() {
 					}
 					return binaryBigRatOp(u, (*big.Rat).Quo, v) // True division.
 				},
+				floatType: func(c Context, u, v Value) Value {
+					if v.(Float) == 0 {
+						Errorf("division by zero")
+					}
+					return binaryFloatOp(u, func(x, y float64) float64 { return x / y }, v)
+				},
 				bigFloatType: func(c Context, u, v Value) Value {
 					return binaryBigFloatOp(c, u, (*big.Float).Quo, v)
 				},
+				intervalType: func(c Context, u, v Value) Value {
+					return intervalQuo(c, u.(Interval), v.(Interval))
+				},
+				complex128Type: func(c Context, u, v Value) Value {
+					return binaryComplex128Op(c, u, "/", func(x, y complex128) complex128 { return x / y }, v)
+				},
 				complexType: func(c Context, u, v Value) Value {
 					return binaryComplexOp(c, u, (Complex).Quo, v)
 				},
@@ -324,6 +381,9 @@ func init() {
 					}
 					return binaryBigIntOp(u, (*big.Int).Quo, v) // Go-like division.
 				},
+				floatType: func(c Context, u, v Value) Value {
+					return machineFloat(floatDiv(u, v, false))
+				},
 				bigRatType:   nil, // Not defined for rationals. Use div.
 				bigFloatType: nil,
 				complexType:  nil,
@@ -347,6 +407,9 @@ func init() {
 					}
 					return binaryBigIntOp(u, (*big.Int).Rem, v) // Go-like modulo.
 				},
+				floatType: func(c Context, u, v Value) Value {
+					return machineFloat(floatMod(u, v, false))
+				},
 				bigRatType:   nil, // Not defined for rationals. Use mod.
 				bigFloatType: nil,
 				complexType:  nil,
@@ -364,6 +427,9 @@ func init() {
 					}
 					return binaryBigIntOp(u, (*big.Int).Div, v) // Euclidean division.
 				},
+				floatType: func(c Context, u, v Value) Value {
+					return machineFloat(floatDiv(u, v, true))
+				},
 				bigRatType:   nil, // Not defined for rationals. Use div.
 				bigFloatType: nil,
 				complexType:  nil,
@@ -381,6 +447,9 @@ func init() {
 					}
 					return binaryBigIntOp(u, (*big.Int).Mod, v) // Euclidian modulo.
 				},
+				floatType: func(c Context, u, v Value) Value {
+					return machineFloat(floatMod(u, v, true))
+				},
 				bigRatType:   nil, // Not defined for rationals. Use mod.
 				bigFloatType: nil,
 				complexType:  nil,
@@ -423,12 +492,21 @@ func init() {
 						positive = false
 						rexp = c.EvalUnary("-", v).toType("**", c.Config(), bigRatType).(BigRat)
 					}
+					rat := u.(BigRat)
 					if !rexp.IsInt() {
-						// Lift to float.
-						return c.EvalBinary(floatSelf(c, u), "**", floatSelf(c, v))
+						// A perfect power of a positive number has an exact
+						// root: (n/d)**(p/q) is ((n/d)**(1/q))**p.
+						root, ok := (*big.Rat)(nil), false
+						if q := rexp.Denom(); rat.Sign() > 0 && q.IsUint64() && q.Uint64() <= maxInt {
+							root, ok = ratRoot(rat.Rat, uint(q.Uint64()))
+						}
+						if !ok {
+							// Lift to float.
+							return c.EvalBinary(floatSelf(c, u), "**", floatSelf(c, v))
+						}
+						rat = BigRat{root}
 					}
 					exp := rexp.Num()
-					rat := u.(BigRat)
 					num := new(big.Int).Set(rat.Num())
 					den := new(big.Int).Set(rat.Denom())
 					bigIntExp(c, num, num, exp)
@@ -441,7 +519,24 @@ func init() {
 					}
 					return z.shrink()
 				},
+				floatType: func(c Context, u, v Value) Value {
+					x, y := u.(Float), v.(Float)
+					switch {
+					case x == 0 && y < 0:
+						Errorf("negative exponent of zero")
+					case x < 0 && y != Float(math.Trunc(float64(y))):
+						// The result is complex.
+						return binaryFloatFallback(c, u, BinaryOps["**"].(*binaryOp), v)
+					}
+					return binaryFloatOp(u, math.Pow, v)
+				},
 				bigFloatType: func(c Context, u, v Value) Value { return power(c, u, v) },
+				intervalType: func(c Context, u, v Value) Value {
+					return intervalPow(c, u.(Interval), v.(Interval))
+				},
+				complex128Type: func(c Context, u, v Value) Value {
+					return binaryComplex128Op(c, u, "**", cmplx.Pow, v)
+				},
 				complexType: func(c Context, u, v Value) Value {
 					base := u.(Complex)
 					exp := v.(Complex)
@@ -508,12 +603,137 @@ func init() {
 				bigIntType:   logBaseU,
 				bigRatType:   logBaseU,
 				bigFloatType: logBaseU,
+				intervalType: func(c Context, u, v Value) Value {
+					return intervalQuo(c, intervalLog(c, v.(Interval)), intervalLog(c, u.(Interval)))
+				},
 				complexType: func(c Context, u, v Value) Value {
 					return binaryComplexOp(c, u, (Complex).LogBaseU, v)
 				},
 			},
 		},
 
+		{
+			name:        "beta",
+			elementwise: true,
+			whichType:   binaryArithType,
+			fn: [numType]binaryFn{
+				intType:      beta,
+				bigIntType:   beta,
+				bigRatType:   beta,
+				bigFloatType: beta,
+				complexType:  beta,
+			},
+		},
+
+		{
+			name:        "besselj",
+			elementwise: true,
+			whichType:   binaryArithType,
+			fn: [numType]binaryFn{
+				intType:      besselJ,
+				bigIntType:   besselJ,
+				bigRatType:   besselJ,
+				bigFloatType: besselJ,
+				complexType:  besselJ,
+			},
+		},
+
+		{
+			name:        "bessely",
+			elementwise: true,
+			whichType:   binaryArithType,
+			fn: [numType]binaryFn{
+				intType:      besselY,
+				bigIntType:   besselY,
+				bigRatType:   besselY,
+				bigFloatType: besselY,
+				complexType:  besselY,
+			},
+		},
+
+		{
+			name:        "gcd",
+			elementwise: true,
+			whichType:   binaryArithType,
+			fn: [numType]binaryFn{
+				intType:    gcd,
+				bigIntType: gcd,
+			},
+		},
+
+		{
+			name:        "lcm",
+			elementwise: true,
+			whichType:   binaryArithType,
+			fn: [numType]binaryFn{
+				intType:    lcm,
+				bigIntType: lcm,
+			},
+		},
+
+		{
+			name:        "modinv",
+			elementwise: true,
+			whichType:   binaryArithType,
+			fn: [numType]binaryFn{
+				intType:    modinv,
+				bigIntType: modinv,
+			},
+		},
+
+		{
+			name:        "iroot",
+			elementwise: true,
+			whichType:   binaryArithType,
+			fn: [numType]binaryFn{
+				intType:    iroot,
+				bigIntType: iroot,
+			},
+		},
+
+		{
+			name:        "jacobi",
+			elementwise: true,
+			whichType:   binaryArithType,
+			fn: [numType]binaryFn{
+				intType:    jacobi,
+				bigIntType: jacobi,
+			},
+		},
+
+		{
+			name:      "cf",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				intType:      cfN,
+				bigIntType:   cfN,
+				bigRatType:   cfN,
+				floatType:    cfN,
+				bigFloatType: cfN,
+			},
+		},
+
+		{
+			name:        "bestrat",
+			elementwise: true,
+			whichType:   binaryArithType,
+			fn: [numType]binaryFn{
+				intType:      bestrat,
+				bigIntType:   bestrat,
+				bigRatType:   bestrat,
+				floatType:    bestrat,
+				bigFloatType: bestrat,
+			},
+		},
+
+		{
+			name:      "powmod",
+			whichType: atLeastVectorType,
+			fn: [numType]binaryFn{
+				vectorType: powmod,
+			},
+		},
+
 		{
 			name:        "!",
 			elementwise: true,
@@ -629,10 +849,19 @@ func init() {
 					i, j := u.(BigRat), v.(BigRat)
 					return toInt(i.Cmp(j.Rat) == 0)
 				},
+				floatType: func(c Context, u, v Value) Value {
+					return toInt(u.(Float) == v.(Float))
+				},
 				bigFloatType: func(c Context, u, v Value) Value {
 					i, j := u.(BigFloat), v.(BigFloat)
 					return toInt(i.Cmp(j.Float) == 0)
 				},
+				intervalType: func(c Context, u, v Value) Value {
+					return intervalEqual(c, u.(Interval), v.(Interval))
+				},
+				complex128Type: func(c Context, u, v Value) Value {
+					return toInt(u.(Complex128) == v.(Complex128))
+				},
 				complexType: func(c Context, u, v Value) Value {
 					i, j := u.(Complex), v.(Complex)
 					return toInt(i.Cmp(c, j))
@@ -659,10 +888,19 @@ func init() {
 					i, j := u.(BigRat), v.(BigRat)
 					return toInt(i.Cmp(j.Rat) != 0)
 				},
+				floatType: func(c Context, u, v Value) Value {
+					return toInt(u.(Float) != v.(Float))
+				},
 				bigFloatType: func(c Context, u, v Value) Value {
 					i, j := u.(BigFloat), v.(BigFloat)
 					return toInt(i.Cmp(j.Float) != 0)
 				},
+				intervalType: func(c Context, u, v Value) Value {
+					return c.EvalUnary("not", intervalEqual(c, u.(Interval), v.(Interval)))
+				},
+				complex128Type: func(c Context, u, v Value) Value {
+					return toInt(u.(Complex128) != v.(Complex128))
+				},
 				complexType: func(c Context, u, v Value) Value {
 					i, j := u.(Complex), v.(Complex)
 					return toInt(!i.Cmp(c, j))
@@ -689,10 +927,16 @@ func init() {
 					i, j := u.(BigRat), v.(BigRat)
 					return toInt(i.Cmp(j.Rat) < 0)
 				},
+				floatType: func(c Context, u, v Value) Value {
+					return toInt(u.(Float) < v.(Float))
+				},
 				bigFloatType: func(c Context, u, v Value) Value {
 					i, j := u.(BigFloat), v.(BigFloat)
 					return toInt(i.Cmp(j.Float) < 0)
 				},
+				intervalType: func(c Context, u, v Value) Value {
+					return intervalLess(c, u.(Interval), v.(Interval), false)
+				},
 			},
 		},
 
@@ -715,10 +959,16 @@ func init() {
 					i, j := u.(BigRat), v.(BigRat)
 					return toInt(i.Cmp(j.Rat) <= 0)
 				},
+				floatType: func(c Context, u, v Value) Value {
+					return toInt(u.(Float) <= v.(Float))
+				},
 				bigFloatType: func(c Context, u, v Value) Value {
 					i, j := u.(BigFloat), v.(BigFloat)
 					return toInt(i.Cmp(j.Float) <= 0)
 				},
+				intervalType: func(c Context, u, v Value) Value {
+					return intervalLess(c, u.(Interval), v.(Interval), true)
+				},
 			},
 		},
 
@@ -741,10 +991,16 @@ func init() {
 					i, j := u.(BigRat), v.(BigRat)
 					return toInt(i.Cmp(j.Rat) > 0)
 				},
+				floatType: func(c Context, u, v Value) Value {
+					return toInt(u.(Float) > v.(Float))
+				},
 				bigFloatType: func(c Context, u, v Value) Value {
 					i, j := u.(BigFloat), v.(BigFloat)
 					return toInt(i.Cmp(j.Float) > 0)
 				},
+				intervalType: func(c Context, u, v Value) Value {
+					return intervalLess(c, v.(Interval), u.(Interval), false)
+				},
 			},
 		},
 
@@ -767,10 +1023,16 @@ func init() {
 					i, j := u.(BigRat), v.(BigRat)
 					return toInt(i.Cmp(j.Rat) >= 0)
 				},
+				floatType: func(c Context, u, v Value) Value {
+					return toInt(u.(Float) >= v.(Float))
+				},
 				bigFloatType: func(c Context, u, v Value) Value {
 					i, j := u.(BigFloat), v.(BigFloat)
 					return toInt(i.Cmp(j.Float) >= 0)
 				},
+				intervalType: func(c Context, u, v Value) Value {
+					return intervalLess(c, v.(Interval), u.(Interval), true)
+				},
 			},
 		},
 
@@ -1219,6 +1481,9 @@ func init() {
 					}
 					return j.shrink()
 				},
+				floatType: func(c Context, u, v Value) Value {
+					return binaryFloatOp(u, math.Min, v)
+				},
 				bigFloatType: func(c Context, u, v Value) Value {
 					i, j := u.(BigFloat), v.(BigFloat)
 					if i.Cmp(j.Float) < 0 {
@@ -1226,6 +1491,9 @@ func init() {
 					}
 					return j.shrink()
 				},
+				intervalType: func(c Context, u, v Value) Value {
+					return intervalMin(c, u.(Interval), v.(Interval))
+				},
 			},
 		},
 
@@ -1260,6 +1528,9 @@ func init() {
 					}
 					return j.shrink()
 				},
+				floatType: func(c Context, u, v Value) Value {
+					return binaryFloatOp(u, math.Max, v)
+				},
 				bigFloatType: func(c Context, u, v Value) Value {
 					i, j := u.(BigFloat), v.(BigFloat)
 					if i.Cmp(j.Float) > 0 {
@@ -1267,6 +1538,20 @@ func init() {
 					}
 					return j.shrink()
 				},
+				intervalType: func(c Context, u, v Value) Value {
+					return intervalMax(c, u.(Interval), v.(Interval))
+				},
+			},
+		},
+
+		{
+			name:        "interval",
+			elementwise: true,
+			whichType:   intervalPromoteType,
+			fn: [numType]binaryFn{
+				intervalType: func(c Context, u, v Value) Value {
+					return newInterval(u.(Interval).lo, v.(Interval).hi)
+				},
 			},
 		},
 
@@ -1567,6 +1852,24 @@ func init() {
 			},
 		},
 
+		{
+			name:      "write",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				charType:   write,
+				vectorType: write,
+			},
+		},
+
+		{
+			name:      "append",
+			whichType: noPromoteType,
+			fn: [numType]binaryFn{
+				charType:   appendFile,
+				vectorType: appendFile,
+			},
+		},
+
 		{
 			// Special case, handled in EvalBinary: don't modify types.
 			name:        "text",
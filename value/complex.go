@@ -35,7 +35,7 @@ func (_ Complex) Rank() int {
 }
 
 func (z Complex) Sprint(conf *config.Config) string {
-	return fmt.Sprintf("%sj%s", z.real.Sprint(conf), z.imag.Sprint(conf))
+	return fmt.Sprintf("%sj%s", elemString(conf, z.real), elemString(conf, z.imag))
 }
 
 func (z Complex) ProgString() string {
@@ -0,0 +1,123 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import (
+	"math/cmplx"
+
+	"robpike.io/ivy/config"
+)
+
+// Complex128 is a complex number held in machine form, with float64 real
+// and imaginary parts. Complex results computed in machine mode are
+// Complex128s. In the promotion order a Complex128 sits below Complex,
+// as Float sits below BigFloat: mixing a Complex128 with a Complex or
+// BigFloat gives a Complex, and operators with no complex128
+// implementation compute with Complex and convert the result back.
+type Complex128 complex128
+
+func (z Complex128) String() string {
+	return "(" + z.Sprint(debugConf) + ")"
+}
+
+func (z Complex128) Rank() int {
+	return 0
+}
+
+func (z Complex128) Sprint(conf *config.Config) string {
+	return z.complex().Sprint(conf)
+}
+
+func (z Complex128) ProgString() string {
+	return z.complex().ProgString()
+}
+
+func (z Complex128) Eval(Context) Value {
+	return z
+}
+
+func (z Complex128) Inner() Value {
+	return z
+}
+
+func (z Complex128) toType(op string, conf *config.Config, which valueType) Value {
+	switch which {
+	case complex128Type:
+		return z
+	case complexType:
+		return z.complex()
+	case vectorType:
+		return NewVector([]Value{z})
+	case matrixType:
+		return NewMatrix([]int{1}, []Value{z})
+	}
+	if imag(z) != 0 {
+		Errorf("%s: cannot convert complex with non-zero imaginary part to %s", op, which)
+		return nil
+	}
+	return Float(real(z)).toType(op, conf, which)
+}
+
+// complex returns z as a Complex with Float or, where exact, Int parts.
+func (z Complex128) complex() Complex {
+	return Complex{real: Float(real(z)).shrink(), imag: Float(imag(z)).shrink()}
+}
+
+// machineComplex returns z as a Complex128 or, if its imaginary part
+// is zero, as a Float shrunk to an Int if possible. It returns nil if
+// z is not finite.
+func machineComplex(z complex128) Value {
+	if cmplx.IsInf(z) || cmplx.IsNaN(z) {
+		return nil
+	}
+	if imag(z) == 0 {
+		return Float(real(z)).shrink()
+	}
+	return Complex128(z)
+}
+
+// unaryComplex128Op applies op to the Complex128 v. If the result is
+// not finite, the operator is evaluated on the Complex value instead,
+// which computes the proper result or reports the error.
+func unaryComplex128Op(c Context, name string, op func(complex128) complex128, v Value) Value {
+	if z := machineComplex(op(complex128(v.(Complex128)))); z != nil {
+		return z
+	}
+	return complex128Fallback(c, UnaryOps[name].(*unaryOp), v)
+}
+
+// binaryComplex128Op is the analog of unaryComplex128Op for binary operators.
+func binaryComplex128Op(c Context, u Value, name string, op func(complex128, complex128) complex128, v Value) Value {
+	if z := machineComplex(op(complex128(u.(Complex128)), complex128(v.(Complex128)))); z != nil {
+		return z
+	}
+	return binaryComplex128Fallback(c, u, BinaryOps[name].(*binaryOp), v)
+}
+
+// complex128Fallback evaluates the unary operator on the Complex128 v by
+// converting v to a Complex, for operators with no complex128
+// implementation or for arguments that complex128 cannot handle.
+// The result is converted back to machine types.
+func complex128Fallback(c Context, op *unaryOp, v Value) Value {
+	conf := c.Config()
+	v = v.toType(op.name, conf, complexType)
+	fn := op.fn[complexType]
+	if fn == nil {
+		Errorf("unary %s not implemented on type %s", op.name, complex128Type)
+	}
+	return machineValue(conf, fn(c, v))
+}
+
+// binaryComplex128Fallback is the analog of complex128Fallback for binary operators.
+func binaryComplex128Fallback(c Context, u Value, op *binaryOp, v Value) Value {
+	conf := c.Config()
+	u = u.toType(op.name, conf, complexType)
+	v = v.toType(op.name, conf, complexType)
+	fn := op.fn[complexType]
+	if fn == nil {
+		Errorf("binary %s not implemented on type %s", op.name, complex128Type)
+	}
+	return machineValue(conf, fn(c, u, v))
+}
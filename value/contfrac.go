@@ -0,0 +1,189 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+// Continued fractions and best rational approximations.
+
+import (
+	"math/big"
+)
+
+// realRat returns the exact value of v, a real scalar, as a *big.Rat.
+// If v is floating point, it also returns its precision in bits;
+// otherwise the precision is zero. The result must not be modified.
+func realRat(c Context, name string, v Value) (*big.Rat, uint) {
+	if r, ok := exactRat(v); ok {
+		return r, 0
+	}
+	switch v := v.(type) {
+	case Float:
+		return new(big.Rat).SetFloat64(float64(v)), 53
+	case BigFloat:
+		if v.IsInf() {
+			Errorf("%s: infinite value", name)
+		}
+		r, _ := v.Rat(nil)
+		return r, v.Prec()
+	}
+	Errorf("%s: non-real value %s", name, v.Sprint(c.Config()))
+	panic("not reached")
+}
+
+// exactInt returns the value of v, which must be an integer, as a *big.Int.
+// Unlike bigIntOf, it accepts integral values of any real type.
+func exactInt(c Context, name string, v Value) *big.Int {
+	r, _ := realRat(c, name, v)
+	if !r.IsInt() {
+		Errorf("%s: non-integer %s", name, v.Sprint(c.Config()))
+	}
+	return r.Num()
+}
+
+// convergent holds the state of the recurrence that computes the
+// convergents h/k of a continued fraction from its terms.
+type convergent struct {
+	h0, h1 *big.Int // Numerators of the previous two convergents.
+	k0, k1 *big.Int // Denominators of the previous two convergents.
+}
+
+func newConvergent() *convergent {
+	return &convergent{
+		h0: big.NewInt(0), h1: big.NewInt(1),
+		k0: big.NewInt(1), k1: big.NewInt(0),
+	}
+}
+
+// next returns the numerator and denominator of the convergent
+// that follows from term a. It does not update the state.
+func (cv *convergent) next(a *big.Int) (h, k *big.Int) {
+	h = new(big.Int).Mul(a, cv.h1)
+	h.Add(h, cv.h0)
+	k = new(big.Int).Mul(a, cv.k1)
+	k.Add(k, cv.k0)
+	return h, k
+}
+
+// push advances the recurrence by term a.
+func (cv *convergent) push(a *big.Int) {
+	h, k := cv.next(a)
+	cv.h0, cv.h1 = cv.h1, h
+	cv.k0, cv.k1 = cv.k1, k
+}
+
+// contFrac returns the terms of the continued fraction of x, at most max
+// of them if max is non-negative. The first term is floor(x) and the rest
+// are positive. For an exact x the expansion is complete. For a floating
+// point x of precision prec it stops at the first convergent that rounds
+// to x, since later terms describe only rounding error.
+func contFrac(x *big.Rat, prec uint, max int) []Value {
+	num, den := new(big.Int).Set(x.Num()), new(big.Int).Set(x.Denom())
+	var target *big.Float
+	if prec > 0 {
+		target = new(big.Float).SetPrec(prec).SetRat(x)
+	}
+	cv := newConvergent()
+	var terms []Value
+	for den.Sign() != 0 && (max < 0 || len(terms) < max) {
+		a, r := new(big.Int).DivMod(num, den, new(big.Int)) // Euclidean: a is the floor.
+		terms = append(terms, BigInt{a}.shrink())
+		num, den = den, r
+		if target != nil {
+			cv.push(a)
+			q := new(big.Rat).SetFrac(cv.h1, cv.k1)
+			if new(big.Float).SetPrec(prec).SetRat(q).Cmp(target) == 0 {
+				// A final term of 1 can be folded into the one before,
+				// giving the canonical form: 0 9 1 is 0 10.
+				if n := len(terms); n > 1 && a.Cmp(bigOne.Int) == 0 {
+					terms = terms[:n-1]
+					terms[n-2] = BigInt{new(big.Int).Add(bigIntOf(terms[n-2]), a)}.shrink()
+				}
+				break
+			}
+		}
+	}
+	return terms
+}
+
+// cf returns the continued fraction terms of v as a vector.
+func cf(c Context, v Value) Value {
+	x, prec := realRat(c, "cf", v)
+	return NewVector(contFrac(x, prec, -1))
+}
+
+// cfN returns the first u continued fraction terms of v as a vector.
+func cfN(c Context, u, v Value) Value {
+	n := exactInt(c, "cf", u)
+	if n.Sign() < 0 || !n.IsInt64() || n.Int64() > maxInt {
+		Errorf("cf: bad count %s", n)
+	}
+	x, prec := realRat(c, "cf", v)
+	return NewVector(contFrac(x, prec, int(n.Int64())))
+}
+
+// uncf returns the exact value of the continued fraction whose
+// terms are the integers of v.
+func uncf(c Context, v Value) Value {
+	terms := v.(Vector)
+	if len(terms) == 0 {
+		Errorf("uncf: empty vector")
+	}
+	var x *big.Rat
+	for i := len(terms) - 1; i >= 0; i-- {
+		t := terms[i]
+		switch t.(type) {
+		case Int, BigInt:
+		default:
+			Errorf("uncf: non-integer term %s", t.Sprint(c.Config()))
+		}
+		a := new(big.Rat).SetInt(bigIntOf(t))
+		if x != nil {
+			if x.Sign() == 0 {
+				Errorf("uncf: division by zero")
+			}
+			a.Add(a, x.Inv(x))
+		}
+		x = a
+	}
+	return BigRat{x}.shrink()
+}
+
+// bestrat returns the rational nearest to v whose denominator is at most u.
+// It is the last convergent of the continued fraction of v within the
+// limit or, if closer, the largest semiconvergent that follows it.
+func bestrat(c Context, u, v Value) Value {
+	limit := exactInt(c, "bestrat", u)
+	if limit.Sign() <= 0 {
+		Errorf("bestrat: non-positive denominator limit %s", limit)
+	}
+	x, _ := realRat(c, "bestrat", v)
+	if x.Denom().Cmp(limit) <= 0 {
+		return BigRat{x}.shrink()
+	}
+	num, den := new(big.Int).Set(x.Num()), new(big.Int).Set(x.Denom())
+	cv := newConvergent()
+	for {
+		a, r := new(big.Int).DivMod(num, den, new(big.Int))
+		if _, k := cv.next(a); k.Cmp(limit) > 0 {
+			// The semiconvergents use terms t < a: take the largest that fits.
+			t := new(big.Int).Sub(limit, cv.k0)
+			t.Quo(t, cv.k1)
+			h, k := cv.next(t)
+			semi := new(big.Rat).SetFrac(h, k)
+			conv := new(big.Rat).SetFrac(cv.h1, cv.k1)
+			if distance(semi, x).Cmp(distance(conv, x)) < 0 {
+				return BigRat{semi}.shrink()
+			}
+			return BigRat{conv}.shrink()
+		}
+		cv.push(a)
+		num, den = den, r
+	}
+}
+
+// distance returns |x-y|.
+func distance(x, y *big.Rat) *big.Rat {
+	d := new(big.Rat).Sub(x, y)
+	return d.Abs(d)
+}
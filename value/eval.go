@@ -17,19 +17,27 @@ const (
 	charType
 	bigIntType
 	bigRatType
+	floatType
 	bigFloatType
+	intervalType
+	complex128Type
 	complexType
 	vectorType
 	matrixType
 	numType
 )
 
-var typeName = [...]string{"int", "char", "big int", "rational", "float", "complex", "vector", "matrix"}
+var typeName = [...]string{"int", "char", "big int", "rational", "float64", "float", "interval", "complex128", "complex", "vector", "matrix"}
 
 func (t valueType) String() string {
 	return typeName[t]
 }
 
+// TypeName returns the name of the type of v, such as "int" or "vector".
+func TypeName(v Value) string {
+	return whichType(v).String()
+}
+
 type unaryFn func(Context, Value) Value
 
 type unaryOp struct {
@@ -39,12 +47,41 @@ type unaryOp struct {
 }
 
 func (op *unaryOp) EvalUnary(c Context, v Value) Value {
+	conf := c.Config()
+	m := conf.Modulus()
+	if m != nil {
+		if z := modularUnary(c, op.name, v, m); z != nil {
+			return z
+		}
+	}
+	machine := conf.Machine()
+	if machine && m == nil {
+		if z := machineVectorUnary(op.name, v); z != nil {
+			return z
+		}
+	}
+	v = boxVector(v)
 	which := whichType(v)
+	if machine && machineFloatOps[op.name] {
+		switch which {
+		case intType, bigIntType, bigRatType:
+			v, which = v.toType(op.name, conf, floatType), floatType
+		}
+	}
 	fn := op.fn[which]
 	if fn == nil {
+		switch which {
+		case floatType:
+			return floatFallback(c, op, v)
+		case complex128Type:
+			return complex128Fallback(c, op, v)
+		}
 		if op.elementwise {
 			switch which {
 			case vectorType:
+				if machine {
+					return machineValue(conf, unaryVectorOp(c, op.name, v))
+				}
 				return unaryVectorOp(c, op.name, v)
 			case matrixType:
 				return unaryMatrixOp(c, op.name, v)
@@ -52,6 +89,9 @@ func (op *unaryOp) EvalUnary(c Context, v Value) Value {
 		}
 		Errorf("unary %s not implemented on type %s", op.name, which)
 	}
+	if machine {
+		return machineValue(conf, fn(c, v))
+	}
 	return fn(c, v)
 }
 
@@ -74,11 +114,17 @@ func whichType(v Value) valueType {
 		return bigIntType
 	case BigRat:
 		return bigRatType
+	case Float:
+		return floatType
 	case BigFloat:
 		return bigFloatType
+	case Interval:
+		return intervalType
+	case Complex128:
+		return complex128Type
 	case Complex:
 		return complexType
-	case Vector:
+	case Vector, Int64Vector, Float64Vector:
 		return vectorType
 	case *Matrix:
 		return matrixType
@@ -94,10 +140,53 @@ func (op *binaryOp) EvalBinary(c Context, u, v Value) Value {
 		if op.name != "text" {
 			Errorf("internal error: nil whichType")
 		}
-		return op.fn[0](c, u, v)
+		return op.fn[0](c, Boxed(u), Boxed(v))
 	}
-	whichU, whichV := op.whichType(whichType(u), whichType(v))
 	conf := c.Config()
+	m := conf.Modulus()
+	if m != nil {
+		if z := modularBinary(c, u, op.name, v, m); z != nil {
+			return z
+		}
+	}
+	machine := conf.Machine()
+	if machine {
+		if z := machineBinary(u, op.name, v); z != nil {
+			return z
+		}
+		if m == nil {
+			if z := machineVectorBinary(u, op.name, v); z != nil {
+				return z
+			}
+		}
+	}
+	u, v = boxVector(u), boxVector(v)
+	whichU, whichV := op.whichType(whichType(u), whichType(v))
+	if machine && whichV == bigRatType && op.fn[floatType] != nil {
+		// Rational results are computed in float64.
+		whichU, whichV = floatType, floatType
+	}
+	if whichV == complex128Type && (whichType(u) == bigFloatType || whichType(v) == bigFloatType) {
+		// As with Float, mixing with a BigFloat keeps the precision.
+		whichU, whichV = complexType, complexType
+	}
+	var lift valueType // The machine type being computed in a big type.
+	switch {
+	case whichV == floatType && op.fn[floatType] == nil:
+		// No float64 implementation; use BigFloat and convert back.
+		lift = floatType
+		if whichU == floatType {
+			whichU = bigFloatType
+		}
+		whichV = bigFloatType
+	case whichV == complex128Type && op.fn[complex128Type] == nil:
+		// Likewise for complex128, using Complex.
+		lift = complex128Type
+		if whichU == complex128Type {
+			whichU = complexType
+		}
+		whichV = complexType
+	}
 	u = u.toType(op.name, conf, whichU)
 	v = v.toType(op.name, conf, whichV)
 	fn := op.fn[whichV]
@@ -105,13 +194,22 @@ func (op *binaryOp) EvalBinary(c Context, u, v Value) Value {
 		if op.elementwise {
 			switch whichV {
 			case vectorType:
+				if machine {
+					return machineValue(conf, binaryVectorOp(c, u, op.name, v))
+				}
 				return binaryVectorOp(c, u, op.name, v)
 			case matrixType:
 				return binaryMatrixOp(c, u, op.name, v)
 			}
 		}
+		if lift != 0 {
+			whichV = lift // Report the type the user has.
+		}
 		Errorf("binary %s not implemented on type %s", op.name, whichV)
 	}
+	if machine || lift != 0 {
+		return machineValue(conf, fn(c, u, v))
+	}
 	return fn(c, u, v)
 }
 
@@ -296,7 +394,12 @@ func outerProduct(c Context, u Value, op string, v Value) Value {
 func Reduce(c Context, op string, v Value) Value {
 	// We must be right associative; that is the grammar.
 	// -/1 2 3 == 1-2-3 is 1-(2-3) not (1-2)-3. Answer: 2.
-	switch v := v.(type) {
+	if conf := c.Config(); conf.Machine() && conf.Modulus() == nil {
+		if z := machineReduce(op, v); z != nil {
+			return z
+		}
+	}
+	switch v := boxVector(v).(type) {
 	case Int, BigInt, BigRat:
 		return v
 	case Vector:
@@ -344,7 +447,7 @@ func Reduce(c Context, op string, v Value) Value {
 // It gives the successive values of reducing op through v.
 // We must be right associative; that is the grammar.
 func Scan(c Context, op string, v Value) Value {
-	switch v := v.(type) {
+	switch v := boxVector(v).(type) {
 	case Int, BigInt, BigRat:
 		return v
 	case Vector:
@@ -567,6 +670,8 @@ func isTrue(fnName string, v Value) bool {
 		return true // If it's a BigInt, it can't be 0 - that's an Int.
 	case BigRat:
 		return true // If it's a BigRat, it can't be 0 - that's an Int.
+	case Float:
+		return i != 0
 	case BigFloat:
 		return i.Float.Sign() != 0
 	default:
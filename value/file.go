@@ -0,0 +1,181 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+// Reading and writing files from ivy programs.
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// FindFile returns the name of the file to read for the given name.
+// If name is absolute or names an existing file, it is returned unchanged.
+// Otherwise the directories in the list held in the IVYPATH environment
+// variable, which is separated by colons (semicolons on Windows), are
+// searched in order. If the file is found nowhere, name is returned.
+func FindFile(name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	if _, err := os.Stat(name); err == nil {
+		return name
+	}
+	for _, dir := range filepath.SplitList(os.Getenv("IVYPATH")) {
+		if dir == "" {
+			continue
+		}
+		file := filepath.Join(dir, name)
+		if _, err := os.Stat(file); err == nil {
+			return file
+		}
+	}
+	return name
+}
+
+// fileName returns the file name held in v, a char or char vector.
+// It fails if the configuration forbids file access.
+func fileName(c Context, op string, v Value) string {
+	var name string
+	switch v := v.(type) {
+	case Char:
+		name = string(rune(v))
+	case Vector:
+		if len(v) == 0 || !v.AllChars() {
+			Errorf("%s: file name is not a string", op)
+		}
+		name = v.makeString(c.Config(), false)
+	}
+	if c.Config().Sandbox() && name != "-" {
+		Errorf("%s: file access not permitted in sandbox", op)
+	}
+	return name
+}
+
+// readFile returns the contents of the named file, or of
+// standard input if the name is "-". Relative names are
+// found as by FindFile.
+func readFile(c Context, op string, v Value) string {
+	name := fileName(c, op, v)
+	var data []byte
+	var err error
+	if name == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(FindFile(name))
+	}
+	if err != nil {
+		Errorf("%s: %s", op, err)
+	}
+	if !utf8.Valid(data) {
+		Errorf("%s: %s: invalid UTF-8", op, name)
+	}
+	return string(data)
+}
+
+// read returns the contents of the file named by v as a char vector.
+func read(c Context, v Value) Value {
+	return NewCharVector(readFile(c, "read", v))
+}
+
+// lines returns the lines of the file named by v as the rows
+// of a char matrix, padded with blanks. Line terminators,
+// either newline or carriage return and newline, are removed.
+func lines(c Context, v Value) Value {
+	text := readFile(c, "lines", v)
+	text = strings.TrimSuffix(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if text == "" {
+		return NewMatrix([]int{0, 0}, nil)
+	}
+	return NewCharMatrix(strings.Split(text, "\n"))
+}
+
+// dir returns the sorted names of the entries in the directory named
+// by v as the rows of a char matrix. The names of directories end
+// with a slash.
+func dir(c Context, v Value) Value {
+	name := fileName(c, "dir", v)
+	entries, err := os.ReadDir(FindFile(name))
+	if err != nil {
+		Errorf("dir: %s", err)
+	}
+	if len(entries) == 0 {
+		return NewMatrix([]int{0, 0}, nil)
+	}
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name()
+		if e.IsDir() {
+			names[i] += "/"
+		}
+	}
+	return NewCharMatrix(names)
+}
+
+// fileText returns the text to write for u, which must be a char,
+// char vector, or char matrix. Each row of a matrix becomes a line,
+// with trailing blanks removed.
+func fileText(c Context, op string, u Value) string {
+	switch u := u.(type) {
+	case Char:
+		return string(rune(u))
+	case Vector:
+		if u.AllChars() {
+			return u.makeString(c.Config(), false)
+		}
+	case *Matrix:
+		if len(u.shape) == 2 && u.data.AllChars() {
+			var b strings.Builder
+			for i := 0; i < u.shape[0]; i++ {
+				row := u.data[i*u.shape[1] : (i+1)*u.shape[1]]
+				b.WriteString(strings.TrimRight(row.makeString(c.Config(), false), " "))
+				b.WriteByte('\n')
+			}
+			return b.String()
+		}
+	}
+	Errorf("%s: value is not text", op)
+	panic("not reached")
+}
+
+// writeFile writes the text u to the file named by v, truncating the
+// file first unless appending. The name "-" means the standard output.
+// It returns the number of bytes written.
+func writeFile(c Context, op string, u, v Value, flag int) Value {
+	text := fileText(c, op, u)
+	name := fileName(c, op, v)
+	if name == "-" {
+		n, err := io.WriteString(c.Config().Output(), text)
+		if err != nil {
+			Errorf("%s: %s", op, err)
+		}
+		return Int(n)
+	}
+	fd, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|flag, 0666)
+	if err != nil {
+		Errorf("%s: %s", op, err)
+	}
+	n, err := io.WriteString(fd, text)
+	if cerr := fd.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		Errorf("%s: %s", op, err)
+	}
+	return Int(n)
+}
+
+// write writes the text u to the file named by v, replacing its contents.
+func write(c Context, u, v Value) Value {
+	return writeFile(c, "write", u, v, os.O_TRUNC)
+}
+
+// appendFile appends the text u to the file named by v.
+func appendFile(c Context, u, v Value) Value {
+	return writeFile(c, "append", u, v, os.O_APPEND)
+}
@@ -0,0 +1,298 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+
+	"robpike.io/ivy/config"
+)
+
+// Float is an IEEE 754 double-precision floating-point number.
+// Floats arise in machine mode (see config.Machine), which trades the
+// precision of the big types for speed. In the promotion order a Float
+// sits above the exact types and below BigFloat, so mixing a Float with
+// an integer or rational gives a Float, while mixing it with a BigFloat
+// gives a BigFloat. In machine mode, vectors of Floats are stored
+// unboxed as Float64Vectors and complex numbers as Complex128s.
+type Float float64
+
+func (f Float) String() string {
+	return "(" + f.Sprint(debugConf) + ")"
+}
+
+func (f Float) Rank() int {
+	return 0
+}
+
+func (f Float) Sprint(conf *config.Config) string {
+	verb, prec := byte('g'), 12
+	if conf.Format() != "" {
+		v, p, ok := conf.FloatFormat()
+		if ok {
+			verb, prec = v, p
+		}
+	}
+	if base := conf.OutputBase(); conf.Format() == "" && base != 0 && base != 10 {
+		return baseString(big.NewFloat(float64(f)), base, baseDigits(prec, base))
+	}
+	return strconv.FormatFloat(float64(f), verb, prec, 64)
+}
+
+// ProgString prints the shortest string that parses back to f.
+// Outside machine mode it parses as the exact rational value of f.
+func (f Float) ProgString() string {
+	return strconv.FormatFloat(float64(f), 'g', -1, 64)
+}
+
+func (f Float) Eval(Context) Value {
+	return f
+}
+
+func (f Float) Inner() Value {
+	return f
+}
+
+func (f Float) toType(op string, conf *config.Config, which valueType) Value {
+	switch which {
+	case floatType:
+		return f
+	case bigFloatType:
+		// Exact, provided the precision is at least 53 bits.
+		return BigFloat{new(big.Float).SetPrec(conf.FloatPrec()).SetFloat64(float64(f))}
+	case intervalType:
+		return floatInterval(conf, new(big.Float).SetFloat64(float64(f)))
+	case complex128Type:
+		return Complex128(complex(float64(f), 0))
+	case complexType:
+		return newComplexReal(f)
+	case vectorType:
+		return NewVector([]Value{f})
+	case matrixType:
+		return NewMatrix([]int{1}, []Value{f})
+	}
+	Errorf("%s: cannot convert float64 to %s", op, which)
+	return nil
+}
+
+// shrink shrinks, if possible, a Float down to an Int.
+func (f Float) shrink() Value {
+	if minInt <= f && f <= maxInt && f == Float(math.Trunc(float64(f))) {
+		return Int(f)
+	}
+	return f
+}
+
+// machineFloat returns x as a Float, shrunk to an Int if possible.
+func machineFloat(x float64) Value {
+	return checkFloat(x).shrink()
+}
+
+// checkFloat returns x as a Float. Overflow and invalid operations
+// are errors, not infinities and NaNs.
+func checkFloat(x float64) Float {
+	if math.IsInf(x, 0) {
+		Errorf("float64 overflow")
+	}
+	if math.IsNaN(x) {
+		Errorf("float64 result is not a number")
+	}
+	return Float(x)
+}
+
+// unaryFloatOp applies op to the Float v.
+func unaryFloatOp(op func(float64) float64, v Value) Value {
+	return machineFloat(op(float64(v.(Float))))
+}
+
+// binaryFloatOp applies op to the Floats u and v.
+func binaryFloatOp(u Value, op func(float64, float64) float64, v Value) Value {
+	return machineFloat(op(float64(u.(Float)), float64(v.(Float))))
+}
+
+// floatMod returns u modulo v, which must be Floats. The result has the
+// sign of u, as for imod, or if euclid is set is non-negative, as for mod.
+func floatMod(u, v Value, euclid bool) float64 {
+	x, y := float64(u.(Float)), float64(v.(Float))
+	if y == 0 {
+		Errorf("modulo by zero")
+	}
+	r := math.Mod(x, y)
+	if euclid && r < 0 {
+		r += math.Abs(y)
+	}
+	return r
+}
+
+// floatDiv returns the integer quotient of u and v, which must be Floats,
+// truncated as for idiv or, if euclid is set, consistent with floatMod
+// as for div: u = v*(u div v) + u mod v.
+func floatDiv(u, v Value, euclid bool) float64 {
+	x, y := float64(u.(Float)), float64(v.(Float))
+	if y == 0 {
+		Errorf("division by zero")
+	}
+	if !euclid {
+		return math.Trunc(x / y)
+	}
+	return math.Round((x - floatMod(u, v, true)) / y)
+}
+
+// floatFallback evaluates the unary operator on the Float v by converting
+// v to a BigFloat, for operators with no float64 implementation or for
+// arguments, such as negative square roots, that float64 cannot handle.
+// The result is converted back to machine types.
+func floatFallback(c Context, op *unaryOp, v Value) Value {
+	conf := c.Config()
+	v = v.toType(op.name, conf, bigFloatType)
+	fn := op.fn[bigFloatType]
+	if fn == nil {
+		Errorf("unary %s not implemented on type %s", op.name, floatType)
+	}
+	return machineValue(conf, fn(c, v))
+}
+
+// binaryFloatFallback is the analog of floatFallback for binary operators.
+func binaryFloatFallback(c Context, u Value, op *binaryOp, v Value) Value {
+	conf := c.Config()
+	u = u.toType(op.name, conf, bigFloatType)
+	v = v.toType(op.name, conf, bigFloatType)
+	fn := op.fn[bigFloatType]
+	if fn == nil {
+		Errorf("binary %s not implemented on type %s", op.name, floatType)
+	}
+	return machineValue(conf, fn(c, u, v))
+}
+
+// machineValue converts a value computed with the big types back to
+// machine types: BigFloats and BigRats become Floats, Complexes become
+// Complex128s and, in machine mode, BigInts wrap around to 64 bits and
+// vectors of machine numbers are stored unboxed. Other values are
+// returned unchanged.
+func machineValue(conf *config.Config, v Value) Value {
+	switch v := v.(type) {
+	case BigInt:
+		if conf.Machine() {
+			return wrapInt(v.Int)
+		}
+	case BigRat:
+		f, _ := v.Float64()
+		return checkFloat(f)
+	case BigFloat:
+		f, _ := v.Float64()
+		return checkFloat(f)
+	case Complex:
+		re, im := machineValue(conf, v.real), machineValue(conf, v.imag)
+		x, ok := float64Of(re)
+		y, ok2 := float64Of(im)
+		if !ok || !ok2 {
+			return Complex{real: re, imag: im}.shrink()
+		}
+		return machineComplex(complex(x, y))
+	case Vector:
+		if conf.Machine() {
+			return unbox(v)
+		}
+	}
+	return v
+}
+
+// float64Of returns the value of v if it is an Int or Float.
+func float64Of(v Value) (float64, bool) {
+	switch v := v.(type) {
+	case Int:
+		return float64(v), true
+	case Float:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// wrapInt returns the low 64 bits of x as a two's complement integer.
+func wrapInt(x *big.Int) Value {
+	u := x.Uint64() // Low 64 bits of |x|.
+	if x.Sign() < 0 {
+		u = -u
+	}
+	return Int(int64(u)).maybeBig()
+}
+
+// int64Of returns the value of v if it is an integer that fits in an int64.
+func int64Of(v Value) (int64, bool) {
+	switch v := v.(type) {
+	case Int:
+		return int64(v), true
+	case BigInt:
+		if v.IsInt64() {
+			return v.Int64(), true
+		}
+	}
+	return 0, false
+}
+
+// machineBinary evaluates in machine mode the integer operators whose
+// results wrap around rather than grow. It returns nil if op or the
+// operands do not qualify, and the operation should proceed normally.
+func machineBinary(u Value, op string, v Value) Value {
+	switch op {
+	case "+", "-", "*", "**":
+	default:
+		return nil
+	}
+	x, ok := int64Of(u)
+	if !ok {
+		return nil
+	}
+	y, ok := int64Of(v)
+	if !ok {
+		return nil
+	}
+	var z int64
+	switch op {
+	case "+":
+		z = x + y
+	case "-":
+		z = x - y
+	case "*":
+		z = x * y
+	case "**":
+		if y < 0 {
+			return nil // Not an integer.
+		}
+		z = 1
+		for ; y > 0; y >>= 1 {
+			if y&1 != 0 {
+				z *= x
+			}
+			x *= x
+		}
+	}
+	return Int(z).maybeBig()
+}
+
+// machineFloatOps are the unary operators that, in machine mode, compute
+// in float64 even for exact arguments, because their results are
+// generally irrational.
+var machineFloatOps = map[string]bool{
+	"/":     true,
+	"**":    true,
+	"acos":  true,
+	"acosh": true,
+	"asin":  true,
+	"asinh": true,
+	"atan":  true,
+	"atanh": true,
+	"cos":   true,
+	"cosh":  true,
+	"float": true,
+	"log":   true,
+	"sin":   true,
+	"sinh":  true,
+	"sqrt":  true,
+	"tan":   true,
+	"tanh":  true,
+}
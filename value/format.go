@@ -32,7 +32,14 @@ func fmtText(c Context, u, v Value) Value {
 	}
 	var b bytes.Buffer
 	switch val := v.(type) {
-	case Int, BigInt, BigRat, BigFloat, Char, Complex:
+	case BigRat:
+		if verb == 'm' {
+			// A mixed number by itself is printed with a blank.
+			b.WriteString(exactFormat(config, format, verb, val.Rat, true))
+			break
+		}
+		formatOne(c, &b, format, verb, val)
+	case Int, BigInt, Float, BigFloat, Char, Complex:
 		formatOne(c, &b, format, verb, val)
 	case Vector:
 		if val.AllChars() && strings.ContainsRune("boOqsvxX", rune(verb)) {
@@ -131,7 +138,7 @@ Loop:
 		// Special case for %%: go on to next verb.
 		case '%':
 			return verbOf(s[i+1:])
-		case 'b', 'c', 'd', 'e', 'E', 'f', 'F', 'g', 'G', 'o', 'O', 'q', 's', 't', 'U', 'v', 'x', 'X':
+		case 'b', 'c', 'd', 'e', 'E', 'f', 'F', 'g', 'G', 'm', 'o', 'O', 'q', 'r', 's', 't', 'U', 'v', 'x', 'X':
 			return byte(c)
 		default:
 			break Loop
@@ -145,6 +152,7 @@ Loop:
 // How it does this depends on the format, permitting us to use %d on
 // floats and rationals, for example.
 func formatOne(c Context, w io.Writer, format string, verb byte, v Value) {
+	v = Boxed(v)
 	switch verb {
 	case 't': // Boolean. TODO: Should be 0 or 1, but that's messy. Odd case anyway.
 		fmt.Fprintf(w, format, toBool(v))
@@ -162,6 +170,8 @@ func formatOne(c Context, w io.Writer, format string, verb byte, v Value) {
 		case BigRat:
 			i, _ := val.Float64()
 			fmt.Fprintf(w, format, int64(i))
+		case Float:
+			fmt.Fprintf(w, format, int64(val))
 		case BigFloat:
 			i, _ := val.Int64()
 			fmt.Fprintf(w, format, i)
@@ -181,6 +191,8 @@ func formatOne(c Context, w io.Writer, format string, verb byte, v Value) {
 		case BigRat:
 			i, _ := val.Float64()
 			fmt.Fprintf(w, format, string(int32(i)))
+		case Float:
+			fmt.Fprintf(w, format, string(int32(val)))
 		case BigFloat:
 			i, _ := val.Int64()
 			fmt.Fprintf(w, format, string(int32(i)))
@@ -203,6 +215,12 @@ func formatOne(c Context, w io.Writer, format string, verb byte, v Value) {
 			fmt.Fprintf(w, format, val.Num())
 			fmt.Fprint(w, "/")
 			fmt.Fprintf(w, format, val.Denom())
+		case Float:
+			if verb == 'x' || verb == 'X' {
+				fmt.Fprintf(w, format, float64(val))
+				return
+			}
+			fmt.Fprintf(w, format, int64(val))
 		case BigFloat:
 			// Hex float format is special, but big.Float does not implement 'X'.
 			switch verb {
@@ -220,6 +238,23 @@ func formatOne(c Context, w io.Writer, format string, verb byte, v Value) {
 			formatOne(c, w, format, verb, val.Imag())
 		}
 		return
+	case 'r', 'm':
+		// Ivy's own formats for exact values.
+		switch val := v.(type) {
+		case Int:
+			fmt.Fprint(w, exactFormat(c.Config(), format, verb, big.NewRat(int64(val), 1), false))
+		case BigInt:
+			fmt.Fprint(w, exactFormat(c.Config(), format, verb, new(big.Rat).SetInt(val.Int), false))
+		case BigRat:
+			fmt.Fprint(w, exactFormat(c.Config(), format, verb, val.Rat, false))
+		case Complex:
+			formatOne(c, w, format, verb, val.Real())
+			fmt.Fprint(w, "j")
+			formatOne(c, w, format, verb, val.Imag())
+		default:
+			// Floating-point values are printed as usual.
+			fmt.Fprintf(w, withVerb(format, 's'), v.Sprint(debugConf))
+		}
 	case 'e', 'E', 'f', 'F', 'g', 'G':
 		f := newFloat(c)
 		switch val := v.(type) {
@@ -235,6 +270,8 @@ func formatOne(c Context, w io.Writer, format string, verb byte, v Value) {
 		case BigRat:
 			f.SetRat(val.Rat)
 			fmt.Fprintf(w, format, f)
+		case Float:
+			fmt.Fprintf(w, format, float64(val))
 		case BigFloat:
 			fmt.Fprintf(w, format, val.Float)
 		case Complex:
@@ -253,3 +290,144 @@ func formatOne(c Context, w io.Writer, format string, verb byte, v Value) {
 		fmt.Fprintf(w, format, v)
 	}
 }
+
+// withVerb returns format with its verb, the first after a percent sign
+// other than %%, replaced by verb.
+func withVerb(format string, verb byte) string {
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		if i < len(format) && format[i] == '%' {
+			continue
+		}
+		for i < len(format) && strings.IndexByte("+-# 0.123456789", format[i]) >= 0 {
+			i++
+		}
+		if i < len(format) {
+			return format[:i] + string(verb) + format[i+1:]
+		}
+	}
+	return format
+}
+
+// exactFormat formats x using format, whose verb is one of those
+// reported by Config.ExactFormat. Flags and width apply to the
+// resulting string, as for %s. If alone is false, x is one element
+// of a larger value and, in the mixed-number format, is printed
+// without a blank; see mixedString.
+func exactFormat(conf *config.Config, format string, verb byte, x *big.Rat, alone bool) string {
+	var s string
+	switch verb {
+	case 'r':
+		s = repeatingString(conf, x)
+	case 'm':
+		s = mixedString(x, alone)
+	default:
+		Errorf("unknown exact format %%%c", verb)
+	}
+	return fmt.Sprintf(withVerb(format, 's'), s)
+}
+
+// elemString returns v printed as one element of a vector, matrix, or
+// complex number. It differs from v.Sprint only for values whose printed
+// form has a blank, which would split the element in two: mixed numbers
+// and intervals.
+func elemString(conf *config.Config, v Value) string {
+	switch v := v.(type) {
+	case BigRat:
+		if conf.ExactFormat() == 'm' {
+			return exactFormat(conf, conf.Format(), 'm', v.Rat, false)
+		}
+	case Interval:
+		return v.sprint(conf, ",")
+	}
+	return v.Sprint(conf)
+}
+
+// repeatingString returns the exact decimal expansion of x, with the
+// repeating part, if any, in parentheses: 1/6 is 0.1(6). If the expansion
+// has more digits after the point than the maximum set by )maxdigits,
+// it returns x as a fraction instead.
+func repeatingString(conf *config.Config, x *big.Rat) string {
+	sign := ""
+	if x.Sign() < 0 {
+		sign = "-"
+	}
+	den := x.Denom()
+	q, r := new(big.Int).QuoRem(new(big.Int).Abs(x.Num()), den, new(big.Int))
+	if r.Sign() == 0 {
+		return sign + q.String()
+	}
+	// The digits before the repeating part number the larger
+	// of the powers of 2 and 5 in the denominator.
+	pre := int(den.TrailingZeroBits())
+	d := new(big.Int).Rsh(den, uint(pre))
+	five := big.NewInt(5)
+	var m big.Int
+	for fives := 1; ; fives++ {
+		d.QuoRem(d, five, &m)
+		if m.Sign() != 0 {
+			if fives-1 > pre {
+				pre = fives - 1
+			}
+			break
+		}
+	}
+	max := int(conf.MaxDigits())
+	var b strings.Builder
+	digit := new(big.Int)
+	ten := big.NewInt(10)
+	n := 0
+	// next appends the next digit to b, reporting whether there is room for it.
+	next := func() bool {
+		r.Mul(r, ten)
+		digit.QuoRem(r, den, r)
+		b.WriteString(digit.String())
+		n++
+		return max == 0 || n <= max
+	}
+	for i := 0; i < pre; i++ {
+		if !next() {
+			return x.RatString()
+		}
+	}
+	if r.Sign() != 0 {
+		// The expansion now repeats from remainder r.
+		start := new(big.Int).Set(r)
+		b.WriteByte('(')
+		for {
+			if !next() {
+				return x.RatString()
+			}
+			if r.Cmp(start) == 0 {
+				break
+			}
+		}
+		b.WriteByte(')')
+	}
+	return sign + q.String() + "." + b.String()
+}
+
+// mixedString returns x as a mixed number, an integer and a proper
+// fraction: 1 1/2 or -1 1/2. If alone is false, x is one element of a
+// larger value, where elements are separated by blanks, so the parts
+// are instead joined by their sign: 1+1/2 or -1-1/2.
+func mixedString(x *big.Rat, alone bool) string {
+	if x.IsInt() {
+		return x.Num().String()
+	}
+	q, r := new(big.Int).QuoRem(x.Num(), x.Denom(), new(big.Int))
+	if q.Sign() == 0 {
+		return x.RatString()
+	}
+	sep := " "
+	if !alone {
+		sep = "+"
+		if r.Sign() < 0 {
+			sep = "-"
+		}
+	}
+	return fmt.Sprintf("%s%s%s/%s", q, sep, r.Abs(r), x.Denom())
+}
@@ -29,7 +29,7 @@ func (ix *indexState) init(context Context, top, left Expr, index []Expr) {
 	ix.indexes = make([]Vector, len(index))
 	ix.xshape = nil // common case - scalar indexes covering entire rank → scalar result
 	for i := len(index) - 1; i >= 0; i-- {
-		x := index[i].Eval(context).Inner()
+		x := boxVector(index[i].Eval(context).Inner())
 		switch x := x.(type) {
 		default:
 			Errorf("invalid index %s (%s) in %s", index[i].ProgString(), whichType(x), top.ProgString())
@@ -51,7 +51,9 @@ func (ix *indexState) init(context Context, top, left Expr, index []Expr) {
 
 	// Can now safely evaluate left side
 	// (must wait until indexes have been evaluated, R-to-L).
-	ix.lhs = left.Eval(context)
+	// A vector stored unboxed is indexed as a Vector; assignment
+	// writes in place, so its caller must box the variable first.
+	ix.lhs = boxVector(left.Eval(context))
 	switch lhs := ix.lhs.(type) {
 	default:
 		Errorf("cannot index %s (%v)", left.ProgString(), whichType(lhs))
@@ -159,7 +161,7 @@ func IndexAssign(context Context, top, left Expr, index []Expr, right Expr, rhs
 	// RHS must be scalar or have same shape as indexed expression.
 	var rscalar Value
 	var rslice []Value
-	switch rhs := rhs.(type) {
+	switch rhs := boxVector(rhs).(type) {
 	default:
 		rscalar = rhs
 	case *Matrix:
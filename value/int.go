@@ -6,6 +6,7 @@ package value
 
 import (
 	"fmt"
+	"math/big"
 	"strconv"
 
 	"robpike.io/ivy/config"
@@ -36,6 +37,9 @@ func (i Int) String() string {
 func (i Int) Sprint(conf *config.Config) string {
 	format := conf.Format()
 	if format != "" {
+		if verb := conf.ExactFormat(); verb != 0 {
+			return exactFormat(conf, format, verb, big.NewRat(int64(i), 1), true)
+		}
 		verb, prec, ok := conf.FloatFormat()
 		if ok {
 			return i.floatString(verb, prec)
@@ -144,8 +148,14 @@ func (i Int) toType(op string, conf *config.Config, which valueType) Value {
 		return bigInt64(int64(i))
 	case bigRatType:
 		return bigRatInt64(int64(i))
+	case floatType:
+		return Float(i)
 	case bigFloatType:
 		return bigFloatInt64(conf, int64(i))
+	case intervalType:
+		return ratInterval(conf, big.NewRat(int64(i), 1))
+	case complex128Type:
+		return Complex128(complex(float64(i), 0))
 	case complexType:
 		return newComplexReal(i)
 	case vectorType:
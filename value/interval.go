@@ -0,0 +1,674 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import (
+	"math/big"
+	"strings"
+
+	"robpike.io/ivy/config"
+)
+
+// Interval is a closed interval [lo, hi] of real numbers. Arithmetic,
+// comparison, and sqrt round the endpoints outward, so the exact result
+// is guaranteed to lie within the interval that represents it.
+//
+// The transcendental functions (log, exp, the trigonometric and
+// hyperbolic functions and their inverses) carry no such guarantee.
+// Their endpoints are computed by the BigFloat algorithms, with guardBits
+// of extra precision, and then widened by a margin far larger than any
+// error those algorithms have been seen to make. The algorithms have no
+// proven error bound, however, so neither does the margin.
+//
+// In the promotion order an Interval sits above BigFloat, so mixing an
+// interval with any other real number gives an interval.
+type Interval struct {
+	lo, hi *big.Float
+}
+
+func (i Interval) String() string {
+	return "(" + i.Sprint(debugConf) + ")"
+}
+
+func (i Interval) Rank() int {
+	return 0
+}
+
+// Sprint prints the interval as [lo, hi]. The endpoints are printed
+// with the precision of the %g format and rounded outward, so the
+// printed interval contains the true one.
+func (i Interval) Sprint(conf *config.Config) string {
+	return i.sprint(conf, ", ")
+}
+
+// sprint is Sprint with the endpoints separated by sep. As an element
+// of a vector or matrix, where a blank separates elements, an interval
+// is printed without one: [lo,hi].
+func (i Interval) sprint(conf *config.Config, sep string) string {
+	prec := 12
+	if conf.Format() != "" {
+		_, p, ok := conf.FloatFormat()
+		if ok && p > 0 {
+			prec = p
+		}
+	}
+	lo, hi := i.Bounds(prec)
+	return "[" + lo + sep + hi + "]"
+}
+
+// ProgString returns an expression that evaluates to the interval,
+// with the endpoints printed, rounded outward, to their full precision.
+func (i Interval) ProgString() string {
+	prec := i.lo.Prec()
+	if i.hi.Prec() > prec {
+		prec = i.hi.Prec()
+	}
+	lo, hi := i.Bounds(int(float64(prec)*0.301029995664) + 1) // 10 log 2.
+	return "(" + lo + " interval " + hi + ")"
+}
+
+func (i Interval) Eval(Context) Value {
+	return i
+}
+
+func (i Interval) Inner() Value {
+	return i
+}
+
+func (i Interval) toType(op string, conf *config.Config, which valueType) Value {
+	switch which {
+	case intervalType:
+		return i
+	case vectorType:
+		return NewVector([]Value{i})
+	case matrixType:
+		return NewMatrix([]int{1}, []Value{i})
+	}
+	Errorf("%s: cannot convert interval to %s", op, which)
+	return nil
+}
+
+// Bounds returns the endpoints of the interval formatted with the
+// specified number of significant digits, rounded outward.
+func (i Interval) Bounds(digits int) (lo, hi string) {
+	return boundString(i.lo, digits, false), boundString(i.hi, digits, true)
+}
+
+// down and up return new Floats with the configured precision
+// that round towards -∞ and +∞ respectively.
+func down(conf *config.Config) *big.Float {
+	return newF(conf).SetMode(big.ToNegativeInf)
+}
+
+func up(conf *config.Config) *big.Float {
+	return newF(conf).SetMode(big.ToPositiveInf)
+}
+
+// newInterval returns the interval [lo, hi].
+func newInterval(lo, hi *big.Float) Interval {
+	if lo.Cmp(hi) > 0 {
+		Errorf("interval: lower bound %s exceeds upper bound %s", lo.Text('g', 12), hi.Text('g', 12))
+	}
+	return Interval{lo, hi}
+}
+
+// ratInterval returns the tightest interval containing r.
+func ratInterval(conf *config.Config, r *big.Rat) Interval {
+	return Interval{down(conf).SetRat(r), up(conf).SetRat(r)}
+}
+
+// floatInterval returns the interval containing only f, rounded
+// outward to the configured precision.
+func floatInterval(conf *config.Config, f *big.Float) Interval {
+	return Interval{down(conf).Set(f), up(conf).Set(f)}
+}
+
+// uncertain is the result of a comparison of intervals whose outcome
+// cannot be determined: it might be false (0) or true (1).
+func uncertain(conf *config.Config) Interval {
+	return Interval{newF(conf).SetInt64(0), newF(conf).SetInt64(1)}
+}
+
+// containsZero reports whether zero lies in i.
+func (i Interval) containsZero() bool {
+	return i.lo.Sign() <= 0 && i.hi.Sign() >= 0
+}
+
+// isPoint reports whether i contains a single number.
+func (i Interval) isPoint() bool {
+	return i.lo.Cmp(i.hi) == 0
+}
+
+// boundString formats x with prec significant digits in the style of %g,
+// rounding up if up is set and down otherwise.
+func boundString(x *big.Float, prec int, up bool) string {
+	if x.Sign() == 0 || x.IsInf() {
+		return x.Text('g', prec)
+	}
+	// Find the decimal exponent of the leading digit.
+	s := x.Text('e', prec-1)
+	exp := 0
+	if e := strings.LastIndexByte(s, 'e'); e >= 0 {
+		for _, c := range s[e+2:] {
+			exp = 10*exp + int(c-'0')
+		}
+		if s[e+1] == '-' {
+			exp = -exp
+		}
+	}
+	// Scale x to an integer of prec digits, rounding in the right direction.
+	// The exponent from Text may be off by one, as Text rounds to nearest.
+	r, _ := x.Rat(nil)
+	var digits, sign string
+	for {
+		shift := prec - 1 - exp
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(shift))), nil)
+		num, den := new(big.Int).Set(r.Num()), new(big.Int).Set(r.Denom())
+		if shift > 0 {
+			num.Mul(num, scale)
+		} else {
+			den.Mul(den, scale)
+		}
+		m, rem := new(big.Int).DivMod(num, den, new(big.Int)) // Euclidean: rounds down.
+		if up && rem.Sign() != 0 {
+			m.Add(m, bigOne.Int)
+		}
+		if m.Sign() < 0 {
+			sign = "-"
+			m.Neg(m)
+		}
+		digits = m.String()
+		if len(digits) >= prec {
+			exp += len(digits) - prec // Rounding may have added a digit.
+			break
+		}
+		exp--
+	}
+	// Trim trailing zeros, as %g does.
+	digits = strings.TrimRight(digits, "0")
+	if digits == "" {
+		digits = "0"
+	}
+	if exp < -4 || exp >= prec {
+		mant := digits[:1]
+		if len(digits) > 1 {
+			mant += "." + digits[1:]
+		}
+		esign := '+'
+		if exp < 0 {
+			esign = '-'
+			exp = -exp
+		}
+		return sign + mant + "e" + string(esign) + twoDigits(exp)
+	}
+	if exp < 0 {
+		return sign + "0." + strings.Repeat("0", -exp-1) + digits
+	}
+	if len(digits) <= exp+1 {
+		return sign + digits + strings.Repeat("0", exp+1-len(digits))
+	}
+	return sign + digits[:exp+1] + "." + digits[exp+1:]
+}
+
+// twoDigits formats the exponent n with at least two digits, as %e does.
+func twoDigits(n int) string {
+	s := big.NewInt(int64(n)).String()
+	if len(s) < 2 {
+		s = "0" + s
+	}
+	return s
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// intervalOf returns the value of v, a real scalar, as an Interval.
+func intervalOf(c Context, v Value) Interval {
+	return v.toType("interval", c.Config(), intervalType).(Interval)
+}
+
+// intervalAdd returns the sum of the intervals u and v.
+func intervalAdd(c Context, u, v Interval) Interval {
+	conf := c.Config()
+	return Interval{down(conf).Add(u.lo, v.lo), up(conf).Add(u.hi, v.hi)}
+}
+
+// intervalSub returns the difference of the intervals u and v.
+func intervalSub(c Context, u, v Interval) Interval {
+	conf := c.Config()
+	return Interval{down(conf).Sub(u.lo, v.hi), up(conf).Sub(u.hi, v.lo)}
+}
+
+// intervalNeg returns the negation of the interval v.
+func intervalNeg(c Context, v Interval) Interval {
+	conf := c.Config()
+	return Interval{down(conf).Neg(v.hi), up(conf).Neg(v.lo)}
+}
+
+// intervalExtremes returns the interval spanning the results of op
+// applied to each pair of endpoints of u and v, rounded outward.
+func intervalExtremes(c Context, u Interval, op func(z, x, y *big.Float) *big.Float, v Interval) Interval {
+	conf := c.Config()
+	var lo, hi *big.Float
+	for _, x := range []*big.Float{u.lo, u.hi} {
+		for _, y := range []*big.Float{v.lo, v.hi} {
+			l := op(down(conf), x, y)
+			h := op(up(conf), x, y)
+			if lo == nil || l.Cmp(lo) < 0 {
+				lo = l
+			}
+			if hi == nil || h.Cmp(hi) > 0 {
+				hi = h
+			}
+		}
+	}
+	return Interval{lo, hi}
+}
+
+// intervalMul returns the product of the intervals u and v.
+func intervalMul(c Context, u, v Interval) Interval {
+	return intervalExtremes(c, u, (*big.Float).Mul, v)
+}
+
+// intervalQuo returns the quotient of the intervals u and v.
+func intervalQuo(c Context, u, v Interval) Interval {
+	if v.containsZero() {
+		Errorf("division by interval containing zero")
+	}
+	return intervalExtremes(c, u, (*big.Float).Quo, v)
+}
+
+// intervalPow returns u**v. An integral exponent is computed by
+// multiplication; otherwise u must be positive and u**v is exp(v log u).
+func intervalPow(c Context, u, v Interval) Interval {
+	conf := c.Config()
+	if v.isPoint() && v.lo.IsInt() {
+		n, acc := v.lo.Int64()
+		if acc != big.Exact || n > maxInt || n < -maxInt {
+			Errorf("interval exponent too large")
+		}
+		if n < 0 {
+			one := floatInterval(conf, newF(conf).SetInt64(1))
+			return intervalQuo(c, one, intervalPow(c, u, intervalOf(c, Int(-n))))
+		}
+		return intervalIntPow(c, u, uint64(n))
+	}
+	if u.lo.Sign() <= 0 {
+		Errorf("non-integral power of interval with non-positive values")
+	}
+	return intervalExp(c, intervalMul(c, v, intervalLog(c, u)))
+}
+
+// intervalIntPow returns u**n.
+func intervalIntPow(c Context, u Interval, n uint64) Interval {
+	conf := c.Config()
+	if n == 0 {
+		return floatInterval(conf, newF(conf).SetInt64(1))
+	}
+	// pow returns x**n for x >= 0, rounded down or up.
+	pow := func(x *big.Float, z *big.Float) *big.Float {
+		z.SetInt64(1)
+		y := new(big.Float).SetPrec(z.Prec()).SetMode(z.Mode()).Set(x)
+		for k := n; k > 0; k >>= 1 {
+			if k&1 != 0 {
+				z.Mul(z, y)
+			}
+			y.Mul(y, y)
+		}
+		return z
+	}
+	absLo := new(big.Float).Abs(u.lo)
+	absHi := new(big.Float).Abs(u.hi)
+	switch {
+	case u.lo.Sign() >= 0:
+		return Interval{pow(u.lo, down(conf)), pow(u.hi, up(conf))}
+	case n%2 == 1:
+		// Odd powers are monotonic.
+		lo := pow(absLo, up(conf))
+		lo.Neg(lo)
+		var hi *big.Float
+		if u.hi.Sign() >= 0 {
+			hi = pow(u.hi, up(conf))
+		} else {
+			hi = pow(absHi, down(conf))
+			hi.Neg(hi)
+		}
+		return Interval{lo, hi}
+	case u.hi.Sign() <= 0:
+		return Interval{pow(absHi, down(conf)), pow(absLo, up(conf))}
+	}
+	// An even power of an interval containing zero.
+	if absLo.Cmp(absHi) > 0 {
+		absHi = absLo
+	}
+	return Interval{newF(conf), pow(absHi, up(conf))}
+}
+
+// intervalAbs returns the absolute value of the interval v.
+func intervalAbs(c Context, v Interval) Interval {
+	conf := c.Config()
+	switch {
+	case v.lo.Sign() >= 0:
+		return v
+	case v.hi.Sign() <= 0:
+		return intervalNeg(c, v)
+	}
+	hi := new(big.Float).Neg(v.lo)
+	if hi.Cmp(v.hi) < 0 {
+		hi = v.hi
+	}
+	return Interval{newF(conf), up(conf).Set(hi)}
+}
+
+// intervalMin returns the interval containing the minimum of u and v.
+func intervalMin(c Context, u, v Interval) Interval {
+	lo, hi := u.lo, u.hi
+	if v.lo.Cmp(lo) < 0 {
+		lo = v.lo
+	}
+	if v.hi.Cmp(hi) < 0 {
+		hi = v.hi
+	}
+	return Interval{lo, hi}
+}
+
+// intervalMax returns the interval containing the maximum of u and v.
+func intervalMax(c Context, u, v Interval) Interval {
+	lo, hi := u.lo, u.hi
+	if v.lo.Cmp(lo) > 0 {
+		lo = v.lo
+	}
+	if v.hi.Cmp(hi) > 0 {
+		hi = v.hi
+	}
+	return Interval{lo, hi}
+}
+
+// intervalLess returns the result of u < v, or of u <= v if orEqual is set:
+// 1 if it is certainly true, 0 if it is certainly false, and the interval
+// [0, 1] if it is uncertain.
+func intervalLess(c Context, u, v Interval, orEqual bool) Value {
+	if orEqual {
+		switch {
+		case u.hi.Cmp(v.lo) <= 0:
+			return one
+		case u.lo.Cmp(v.hi) > 0:
+			return zero
+		}
+	} else {
+		switch {
+		case u.hi.Cmp(v.lo) < 0:
+			return one
+		case u.lo.Cmp(v.hi) >= 0:
+			return zero
+		}
+	}
+	return uncertain(c.Config())
+}
+
+// intervalEqual returns the result of u == v: 1 if it is certainly true,
+// 0 if it is certainly false, and the interval [0, 1] if it is uncertain.
+func intervalEqual(c Context, u, v Interval) Value {
+	switch {
+	case u.isPoint() && v.isPoint() && u.lo.Cmp(v.lo) == 0:
+		return one
+	case u.hi.Cmp(v.lo) < 0 || v.hi.Cmp(u.lo) < 0:
+		return zero
+	}
+	return uncertain(c.Config())
+}
+
+// certainly returns 1 if v is certainly non-zero, 0 otherwise.
+func certainly(c Context, v Value) Value {
+	if i, ok := v.(Interval); ok {
+		return toInt(!i.containsZero())
+	}
+	return toInt(toBool(v))
+}
+
+// possibly returns 1 if v is possibly non-zero, 0 otherwise.
+func possibly(c Context, v Value) Value {
+	return toInt(toBool(v))
+}
+
+// slop returns the margin by which an endpoint y = f(x) of an elementary
+// function f, computed with guardBits of extra precision, is widened:
+// (1 + |x| + |y|) * 2**-prec. The values at 0 and 1 that are 0 or 1,
+// such as sin 0 and log 1, are exact and have no margin. The margin is
+// not a proven bound; see the comment on Interval.
+func slop(conf *config.Config, x, y *big.Float) *big.Float {
+	if isZeroOrOne(x) && isZeroOrOne(y) {
+		return newF(conf)
+	}
+	e := up(conf).Abs(x)
+	e.Add(e, new(big.Float).Abs(y))
+	e.Add(e, floatOne)
+	return e.SetMantExp(e, -int(conf.FloatPrec()))
+}
+
+// isZeroOrOne reports whether x is 0 or 1.
+func isZeroOrOne(x *big.Float) bool {
+	return x.Sign() == 0 || x.Cmp(floatOne) == 0
+}
+
+// widen returns the interval [lo-e, hi+e], where e is the margin for
+// lo = f(x) and hi = f(y), rounded outward to the configured precision.
+func widen(conf *config.Config, x, lo, y, hi *big.Float) Interval {
+	return Interval{
+		down(conf).Sub(lo, slop(conf, x, lo)),
+		up(conf).Add(hi, slop(conf, y, hi)),
+	}
+}
+
+// guardContext is a Context whose float precision is guardBits higher
+// than that of the Context it wraps. The endpoints of the transcendental
+// functions are computed in it.
+type guardContext struct {
+	Context
+	conf *config.Config
+}
+
+func (g guardContext) Config() *config.Config {
+	return g.conf
+}
+
+// withGuardBits returns c with guardBits of extra float precision.
+func withGuardBits(c Context) guardContext {
+	conf := *c.Config()
+	conf.SetFloatPrec(conf.FloatPrec() + guardBits)
+	return guardContext{c, &conf}
+}
+
+// monotonic returns the interval containing f(v) for an increasing
+// elementary function f, which is called with the guard context.
+func monotonic(c Context, v Interval, f func(c Context, x *big.Float) *big.Float) Interval {
+	g := withGuardBits(c)
+	return widen(c.Config(), v.lo, f(g, v.lo), v.hi, f(g, v.hi))
+}
+
+// clamp restricts i to lie within [lo, hi].
+func clamp(conf *config.Config, i Interval, lo, hi int64) Interval {
+	l, h := newF(conf).SetInt64(lo), newF(conf).SetInt64(hi)
+	if i.lo.Cmp(l) < 0 {
+		i.lo = l
+	}
+	if i.hi.Cmp(h) > 0 {
+		i.hi = h
+	}
+	return i
+}
+
+// intervalSqrt returns the square root of v. Since big.Float computes
+// square roots correctly rounded, no slop is needed.
+func intervalSqrt(c Context, v Interval) Interval {
+	conf := c.Config()
+	if v.lo.Sign() < 0 {
+		Errorf("sqrt of interval with negative values")
+	}
+	return Interval{down(conf).Sqrt(v.lo), up(conf).Sqrt(v.hi)}
+}
+
+// intervalLog returns the natural logarithm of v.
+func intervalLog(c Context, v Interval) Interval {
+	if v.lo.Sign() <= 0 {
+		Errorf("log of interval with non-positive values")
+	}
+	return monotonic(c, v, func(c Context, x *big.Float) *big.Float {
+		return floatLog(c, x)
+	})
+}
+
+// intervalExp returns e**v.
+func intervalExp(c Context, v Interval) Interval {
+	z := monotonic(c, v, func(c Context, x *big.Float) *big.Float {
+		conf := c.Config()
+		if x.Sign() >= 0 {
+			return floatExp(conf, x)
+		}
+		// Avoid the cancellation in the series for negative x.
+		z := floatExp(conf, newF(conf).Neg(x))
+		return z.Quo(floatOne, z)
+	})
+	if z.lo.Sign() < 0 {
+		z.lo = newFloat(c) // e**x > 0.
+	}
+	return z
+}
+
+// intervalAtan returns the arc tangent of v.
+func intervalAtan(c Context, v Interval) Interval {
+	return monotonic(c, v, func(c Context, x *big.Float) *big.Float {
+		return floatAtan(c, newFloat(c).Set(x))
+	})
+}
+
+// intervalSinCos returns sin v, or cos v if cos is set. Between the
+// endpoints the function may reach its maximum, at max + 2kπ, or its
+// minimum, at max + π + 2kπ; if so that bound is 1 or -1.
+func intervalSinCos(c Context, v Interval, cos bool) Interval {
+	conf := c.Config()
+	twoPi := newF(conf).Mul(floatPi, floatTwo)
+	if new(big.Float).Sub(v.hi, v.lo).Cmp(twoPi) >= 0 {
+		return clamp(conf, Interval{newF(conf).SetInt64(-1), newF(conf).SetInt64(1)}, -1, 1)
+	}
+	g := withGuardBits(c)
+	f := func(x *big.Float) *big.Float {
+		// The argument reduction in floatSin and floatCos modifies x.
+		if cos {
+			return floatCos(g, newFloat(g).Set(x))
+		}
+		return floatSin(g, newFloat(g).Set(x))
+	}
+	ylo, yhi := f(v.lo), f(v.hi)
+	z := widen(conf, v.lo, minFloat(ylo, yhi), v.hi, maxFloat(ylo, yhi))
+	max := newF(conf) // cos has its maximum at 0.
+	if !cos {
+		max.Set(floatHalfPi)
+	}
+	if reaches(conf, v, max, twoPi) {
+		z.hi = newF(conf).SetInt64(1)
+	}
+	if reaches(conf, v, max.Add(max, floatPi), twoPi) {
+		z.lo = newF(conf).SetInt64(-1)
+	}
+	return clamp(conf, z, -1, 1)
+}
+
+// reaches reports whether v might contain a point x0 + 2kπ for some integer k.
+// It errs on the side of reporting true.
+func reaches(conf *config.Config, v Interval, x0, twoPi *big.Float) bool {
+	// The first such point at or above v.lo has k = ceil((v.lo-x0)/2π), and
+	// v is narrower than 2π. To allow for rounding, try the neighbors too.
+	t := newF(conf).Sub(v.lo, x0)
+	t.Quo(t, twoPi)
+	k, _ := t.Int(nil)
+	k.Sub(k, bigOne.Int)
+	x := newF(conf)
+	for i := 0; i < 4; i++ {
+		x.SetInt(k)
+		x.Mul(x, twoPi)
+		x.Add(x, x0)
+		tol := slop(conf, x, floatZero)
+		lo := new(big.Float).Sub(v.lo, tol)
+		hi := new(big.Float).Add(v.hi, tol)
+		if lo.Cmp(x) <= 0 && x.Cmp(hi) <= 0 {
+			return true
+		}
+		k.Add(k, bigOne.Int)
+	}
+	return false
+}
+
+func minFloat(x, y *big.Float) *big.Float {
+	if x.Cmp(y) < 0 {
+		return x
+	}
+	return y
+}
+
+func maxFloat(x, y *big.Float) *big.Float {
+	if x.Cmp(y) > 0 {
+		return x
+	}
+	return y
+}
+
+// intervalTan returns the tangent of v, computed as sin v / cos v.
+func intervalTan(c Context, v Interval) Interval {
+	return intervalQuo(c, intervalSinCos(c, v, false), intervalSinCos(c, v, true))
+}
+
+// intervalSinh returns the hyperbolic sine of v, computed as (e**v - e**-v)/2.
+// Since sinh is increasing, the endpoints are computed separately to
+// avoid the widening that comes from evaluating the formula on intervals.
+func intervalSinh(c Context, v Interval) Interval {
+	lo := sinhOf(c, v.lo)
+	hi := sinhOf(c, v.hi)
+	return Interval{lo.lo, hi.hi}
+}
+
+// sinhOf returns an interval containing sinh x.
+func sinhOf(c Context, x *big.Float) Interval {
+	conf := c.Config()
+	point := floatInterval(conf, x)
+	z := intervalSub(c, intervalExp(c, point), intervalExp(c, intervalNeg(c, point)))
+	two := floatInterval(conf, floatTwo)
+	return intervalQuo(c, z, two)
+}
+
+// intervalCosh returns the hyperbolic cosine of v, computed as (e**v + e**-v)/2.
+// Since cosh is even and increasing for positive arguments, it is evaluated
+// at the endpoints of |v|.
+func intervalCosh(c Context, v Interval) Interval {
+	conf := c.Config()
+	a := intervalAbs(c, v)
+	z := Interval{coshOf(c, a.lo).lo, coshOf(c, a.hi).hi}
+	if z.lo.Cmp(floatOne) < 0 {
+		z.lo = newF(conf).SetInt64(1) // cosh x >= 1.
+	}
+	return z
+}
+
+// coshOf returns an interval containing cosh x.
+func coshOf(c Context, x *big.Float) Interval {
+	conf := c.Config()
+	point := floatInterval(conf, x)
+	z := intervalAdd(c, intervalExp(c, point), intervalExp(c, intervalNeg(c, point)))
+	two := floatInterval(conf, floatTwo)
+	return intervalQuo(c, z, two)
+}
+
+// intervalTanh returns the hyperbolic tangent of v. It is increasing,
+// so the endpoints are computed separately as sinh x / cosh x.
+func intervalTanh(c Context, v Interval) Interval {
+	conf := c.Config()
+	lo := intervalQuo(c, sinhOf(c, v.lo), coshOf(c, v.lo))
+	hi := intervalQuo(c, sinhOf(c, v.hi), coshOf(c, v.hi))
+	return clamp(conf, Interval{lo.lo, hi.hi}, -1, 1)
+}
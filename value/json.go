@@ -0,0 +1,338 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"strings"
+)
+
+/*
+JSON encoding of values is lossless. Each value is encoded as follows:
+
+	int, big int:   a JSON number, 42
+	rational:       {"num": 1, "den": 3}
+	float:          {"float": "3.14159...", "prec": 256}
+	float64:        {"float64": 0.1}
+	complex:        {"re": 1, "im": {"num": 1, "den": 2}}
+	interval:       {"interval": ["1.41...", "1.42..."], "prec": 256}
+	char, string:   a JSON string, "hello"
+	vector:         a JSON array of its elements, [1, 2, 3]
+	matrix:         {"shape": [2, 3], "data": [1, 2, 3, 4, 5, 6]}
+
+The digits of a float or interval bound are the fewest that recover it
+exactly at its precision. A vector holding only chars is a string, but
+the empty vector is the empty array. Decoding accepts the same forms,
+and also true and false, which become 1 and 0. Any JSON number decodes
+exactly, so 0.1 becomes the rational 1/10.
+*/
+
+// MarshalJSON returns the JSON encoding of v.
+func MarshalJSON(v Value) (data []byte, err error) {
+	defer recoverJSON(&err)
+	return json.Marshal(jsonOf(v))
+}
+
+// UnmarshalJSON returns the value encoded by the JSON data.
+func UnmarshalJSON(data []byte) (v Value, err error) {
+	defer recoverJSON(&err)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var x interface{}
+	if err := dec.Decode(&x); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		Errorf("json: extra data after value")
+	}
+	return valueOfJSON(x, true), nil
+}
+
+// recoverJSON turns an ivy error into a returned error.
+func recoverJSON(err *error) {
+	if e := recover(); e != nil {
+		ivyErr, ok := e.(Error)
+		if !ok {
+			panic(e)
+		}
+		*err = ivyErr
+	}
+}
+
+func (i Int) MarshalJSON() ([]byte, error)           { return MarshalJSON(i) }
+func (c Char) MarshalJSON() ([]byte, error)          { return MarshalJSON(c) }
+func (i BigInt) MarshalJSON() ([]byte, error)        { return MarshalJSON(i) }
+func (r BigRat) MarshalJSON() ([]byte, error)        { return MarshalJSON(r) }
+func (f Float) MarshalJSON() ([]byte, error)         { return MarshalJSON(f) }
+func (f BigFloat) MarshalJSON() ([]byte, error)      { return MarshalJSON(f) }
+func (i Interval) MarshalJSON() ([]byte, error)      { return MarshalJSON(i) }
+func (z Complex) MarshalJSON() ([]byte, error)       { return MarshalJSON(z) }
+func (z Complex128) MarshalJSON() ([]byte, error)    { return MarshalJSON(z) }
+func (v Vector) MarshalJSON() ([]byte, error)        { return MarshalJSON(v) }
+func (v Int64Vector) MarshalJSON() ([]byte, error)   { return MarshalJSON(v) }
+func (v Float64Vector) MarshalJSON() ([]byte, error) { return MarshalJSON(v) }
+func (m *Matrix) MarshalJSON() ([]byte, error)       { return MarshalJSON(m) }
+
+// jsonOf returns the value in a form ready for encoding/json.
+func jsonOf(v Value) interface{} {
+	switch v := v.(type) {
+	case Int:
+		return json.Number(v.ProgString())
+	case Char:
+		return string(rune(v))
+	case BigInt:
+		return json.Number(v.Int.String())
+	case BigRat:
+		return map[string]interface{}{
+			"num": json.Number(v.Num().String()),
+			"den": json.Number(v.Denom().String()),
+		}
+	case Float:
+		return map[string]interface{}{"float64": json.Number(v.ProgString())}
+	case BigFloat:
+		return map[string]interface{}{
+			"float": v.Text('g', -1),
+			"prec":  v.Prec(),
+		}
+	case Interval:
+		return map[string]interface{}{
+			"interval": []string{v.lo.Text('g', -1), v.hi.Text('g', -1)},
+			"prec":     v.lo.Prec(),
+		}
+	case Complex128:
+		return jsonOf(v.complex())
+	case Int64Vector:
+		return jsonOf(v.Boxed())
+	case Float64Vector:
+		return jsonOf(v.Boxed())
+	case Complex:
+		return map[string]interface{}{
+			"re": jsonOf(v.real),
+			"im": jsonOf(v.imag),
+		}
+	case Vector:
+		if len(v) > 0 && v.AllChars() {
+			return v.makeString(debugConf, false)
+		}
+		elems := make([]interface{}, len(v))
+		for i, e := range v {
+			elems[i] = jsonOf(e)
+		}
+		return elems
+	case *Matrix:
+		data := make([]interface{}, len(v.data))
+		for i, e := range v.data {
+			data[i] = jsonOf(e)
+		}
+		return map[string]interface{}{
+			"shape": v.shape,
+			"data":  data,
+		}
+	}
+	Errorf("json: cannot encode %T", v)
+	panic("not reached")
+}
+
+// valueOfJSON returns the ivy value for the decoded JSON x.
+// Arrays are permitted only at the top level, if top is set.
+func valueOfJSON(x interface{}, top bool) Value {
+	switch x := x.(type) {
+	case json.Number:
+		return numberOfJSON(string(x))
+	case bool:
+		return toInt(x)
+	case string:
+		runes := []rune(x)
+		if len(runes) == 1 {
+			return Char(runes[0])
+		}
+		elems := make([]Value, len(runes))
+		for i, r := range runes {
+			elems[i] = Char(r)
+		}
+		return NewVector(elems)
+	case []interface{}:
+		if !top {
+			Errorf("json: nested array")
+		}
+		elems := make([]Value, len(x))
+		for i, e := range x {
+			elems[i] = valueOfJSON(e, false)
+			if _, ok := elems[i].(Vector); ok {
+				Errorf("json: vector element must be scalar")
+			}
+		}
+		return NewVector(elems)
+	case map[string]interface{}:
+		return objectOfJSON(x, top)
+	case nil:
+		Errorf("json: null has no value")
+	}
+	Errorf("json: cannot decode %T", x)
+	panic("not reached")
+}
+
+// numberOfJSON returns the exact value of the JSON number s.
+func numberOfJSON(s string) Value {
+	if !strings.ContainsAny(s, ".eE") {
+		i, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			Errorf("json: bad number %s", s)
+		}
+		return BigInt{i}.shrink()
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		Errorf("json: bad number %s", s)
+	}
+	return BigRat{r}.shrink()
+}
+
+// objectOfJSON returns the ivy value for the decoded JSON object x.
+func objectOfJSON(x map[string]interface{}, top bool) Value {
+	has := func(keys ...string) bool {
+		if len(x) != len(keys) {
+			return false
+		}
+		for _, k := range keys {
+			if _, ok := x[k]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+	switch {
+	case has("num", "den"):
+		num, ok1 := x["num"].(json.Number)
+		den, ok2 := x["den"].(json.Number)
+		n, ok3 := new(big.Int).SetString(string(num), 10)
+		d, ok4 := new(big.Int).SetString(string(den), 10)
+		if !ok1 || !ok2 || !ok3 || !ok4 {
+			Errorf("json: bad rational")
+		}
+		if d.Sign() == 0 {
+			Errorf("zero denominator in rational")
+		}
+		return BigRat{new(big.Rat).SetFrac(n, d)}.shrink()
+	case has("float", "prec"):
+		prec := precOfJSON(x["prec"])
+		s, _ := x["float"].(string)
+		return BigFloat{floatOfJSON(s, prec)}
+	case has("float64"):
+		n, _ := x["float64"].(json.Number)
+		f, err := n.Float64()
+		if err != nil {
+			Errorf("json: bad float64 %s", n)
+		}
+		return Float(f)
+	case has("interval", "prec"):
+		prec := precOfJSON(x["prec"])
+		bounds, _ := x["interval"].([]interface{})
+		if len(bounds) != 2 {
+			Errorf("json: interval needs two bounds")
+		}
+		lo, _ := bounds[0].(string)
+		hi, _ := bounds[1].(string)
+		return newInterval(floatOfJSON(lo, prec), floatOfJSON(hi, prec))
+	case has("re", "im"):
+		re := valueOfJSON(x["re"], false)
+		im := valueOfJSON(x["im"], false)
+		for _, part := range []Value{re, im} {
+			switch part.(type) {
+			case Int, BigInt, BigRat, Float, BigFloat:
+			default:
+				Errorf("json: bad complex part %s", part)
+			}
+		}
+		return NewComplex(re, im)
+	case has("shape", "data"):
+		if !top {
+			Errorf("json: nested matrix")
+		}
+		return matrixOfJSON(x["shape"], x["data"])
+	}
+	Errorf("json: unrecognized object")
+	panic("not reached")
+}
+
+// precOfJSON returns the precision of a float in the decoded JSON x.
+func precOfJSON(x interface{}) uint {
+	n, _ := x.(json.Number)
+	prec, err := n.Int64()
+	if err != nil || prec <= 0 || prec > big.MaxPrec {
+		Errorf("json: bad precision %s", n)
+	}
+	return uint(prec)
+}
+
+// floatOfJSON returns the value of the float s with the given precision.
+func floatOfJSON(s string, prec uint) *big.Float {
+	f, ok := new(big.Float).SetPrec(prec).SetString(s)
+	if !ok {
+		Errorf("json: bad float %q", s)
+	}
+	return f
+}
+
+// matrixOfJSON returns the matrix with the decoded JSON shape and data.
+func matrixOfJSON(shape, data interface{}) Value {
+	dims, _ := shape.([]interface{})
+	elems, _ := data.([]interface{})
+	if len(dims) == 0 {
+		Errorf("json: bad matrix shape")
+	}
+	size := 1
+	intShape := make([]int, len(dims))
+	for i, d := range dims {
+		n, _ := d.(json.Number)
+		x, err := n.Int64()
+		if err != nil || x < 0 || x > maxInt {
+			Errorf("json: bad matrix shape")
+		}
+		intShape[i] = int(x)
+		size *= int(x)
+	}
+	if size != len(elems) {
+		Errorf("json: matrix shape does not match data")
+	}
+	values := make([]Value, len(elems))
+	for i, e := range elems {
+		values[i] = valueOfJSON(e, false)
+		if _, ok := values[i].(Vector); ok {
+			Errorf("json: matrix element must be scalar")
+		}
+	}
+	return NewMatrix(intShape, values)
+}
+
+// jsonText returns the JSON encoding of v as a char vector.
+func jsonText(c Context, v Value) Value {
+	data, err := MarshalJSON(v)
+	if err != nil {
+		Errorf("%s", err)
+	}
+	return NewCharVector(string(data))
+}
+
+// unjson returns the value encoded by the JSON text v, a char or char vector.
+func unjson(c Context, v Value) Value {
+	var s string
+	switch v := v.(type) {
+	case Char:
+		s = string(rune(v))
+	case Vector:
+		if !v.AllChars() {
+			Errorf("unjson: value is not a vector of char")
+		}
+		s = v.makeString(c.Config(), false)
+	}
+	x, err := UnmarshalJSON([]byte(s))
+	if err != nil {
+		Errorf("unjson: %s", err)
+	}
+	return x
+}
@@ -5,6 +5,7 @@
 package value
 
 import (
+	"math"
 	"math/big"
 )
 
@@ -26,6 +27,14 @@ func logn(c Context, v Value) Value {
 }
 
 func logBaseU(c Context, u, v Value) Value {
+	// The log of one perfect power to the base of another is rational.
+	if ur, ok := exactRat(u); ok {
+		if vr, ok := exactRat(v); ok {
+			if z, ok := exactLog(ur, vr); ok {
+				return BigRat{z}.shrink()
+			}
+		}
+	}
 	// Handle the integer part exactly when the arguments are exact.
 	var i Int
 	switch u := u.(type) {
@@ -47,6 +56,99 @@ func logBaseU(c Context, u, v Value) Value {
 	return f
 }
 
+// exactLog returns the log of v to base b, and whether it is rational.
+// It is if, and only if, b and v are integer powers of a common base.
+func exactLog(b, v *big.Rat) (*big.Rat, bool) {
+	if b.Sign() <= 0 || v.Sign() <= 0 || b.Cmp(big.NewRat(1, 1)) == 0 {
+		return nil, false
+	}
+	if v.Cmp(big.NewRat(1, 1)) == 0 {
+		return new(big.Rat), true
+	}
+	bBase, bExp := perfectPower(b)
+	vBase, vExp := perfectPower(v)
+	switch {
+	case bBase.Cmp(vBase) == 0:
+	case new(big.Rat).Inv(bBase).Cmp(vBase) == 0:
+		vExp = -vExp
+	default:
+		return nil, false
+	}
+	return big.NewRat(vExp, bExp), true
+}
+
+// perfectPower returns the base and exponent of x = base**exp,
+// where x is positive and not 1, with exp as large as possible.
+func perfectPower(x *big.Rat) (*big.Rat, int64) {
+	exp := int64(1)
+	// Extract prime roots until none is exact. A q'th root exists only
+	// if the numerator and denominator both have at least q bits or are 1.
+	// Computing a root is expensive, so it is attempted only for the q
+	// that pass the cheap test in mayBePower.
+	for q := uint(2); ; q++ {
+		bits := x.Num().BitLen()
+		if d := x.Denom().BitLen(); d > bits {
+			bits = d
+		}
+		if q > uint(bits) {
+			return x, exp
+		}
+		if !isSmallPrime(q) {
+			continue
+		}
+		for mayBePower(x.Num(), q) && mayBePower(x.Denom(), q) {
+			root, ok := ratRoot(x, q)
+			if !ok {
+				break
+			}
+			x = root
+			exp *= int64(q)
+		}
+	}
+}
+
+// mayBePower reports whether the positive integer x might be a q'th power,
+// for prime q. If it returns false, x is certainly not a q'th power.
+// For a prime p = kq+1, the q'th powers modulo p that are not 0 are
+// exactly the residues r with r**k = 1 (mod p), and only one residue
+// in q passes, so checking a few such p rejects almost every non-power.
+func mayBePower(x *big.Int, q uint) bool {
+	if x.BitLen() <= 1 {
+		return true // x is 1.
+	}
+	const nChecks = 4
+	var r, e, m big.Int
+	checks := 0
+	for k := uint64(2); checks < nChecks && k < 1000; k += 2 {
+		p := k*uint64(q) + 1
+		if !isSmallPrime(uint(p)) {
+			continue
+		}
+		checks++
+		r.Mod(x, m.SetUint64(p))
+		if r.Sign() == 0 {
+			continue
+		}
+		if r.Exp(&r, e.SetUint64(k), &m).Cmp(bigOne.Int) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// isSmallPrime reports whether n is prime, by trial division.
+func isSmallPrime(n uint) bool {
+	if n < 2 {
+		return false
+	}
+	for d := uint(2); d*d <= n; d++ {
+		if n%d == 0 {
+			return false
+		}
+	}
+	return true
+}
+
 // intLog returns the integer portion i of log base b of v
 // along with the remaining portion v / b^i.
 func intLog(b, v Int) (i, r Int) {
@@ -103,63 +205,162 @@ func bigIntLog(b, v BigInt) (i Int, r BigInt) {
 	return i, v
 }
 
-// floatLog computes natural log(x) using the Maclaurin series for log(1-x).
+// floatLog computes the natural log of x. Exact powers of 2 and 10 use
+// the cached constants. Arguments near 1 use the series for atanh, which
+// converges quickly there; others use the arithmetic-geometric mean,
+// whose cost grows only logarithmically with the precision.
 func floatLog(c Context, x *big.Float) *big.Float {
 	if x.Sign() <= 0 {
 		Errorf("log of non-positive value")
 	}
-	// Convergence is imperfect at 1, so get it right.
-	if x.Cmp(floatOne) == 0 {
-		return newFloat(c)
+	z := newFloat(c)
+	if k, ok := powerOf2(x); ok {
+		return z.Mul(z.SetInt64(k), floatLog2)
 	}
-	// The series wants x < 1, and log 1/x == -log x, so exploit that.
-	invert := false
-	x = newFloat(c).Set(x) // Don't modify argument!
-	if x.Cmp(floatOne) > 0 {
-		invert = true
-		x.Quo(floatOne, x)
+	if k, ok := powerOf10(x); ok {
+		return z.Mul(z.SetInt64(k), floatLog10)
 	}
-
-	// x = mantissa * 2**exp, and 0.5 <= mantissa < 1.
-	// So log(x) is log(mantissa)+exp*log(2), and 1-x will be
-	// between 0 and 0.5, so the series for 1-x will converge well.
-	// (The series converges slowly in general.)
-	mantissa := newFloat(c)
-	exp2 := x.MantExp(mantissa)
-	exp := newFloat(c).SetInt64(int64(exp2))
-	exp.Mul(exp, floatLog2)
-	if invert {
-		exp.Neg(exp)
+	prec := c.Config().FloatPrec()
+	// log x = 2 atanh y where y = (x-1)/(x+1).
+	y := new(big.Float).SetPrec(prec + guardBits)
+	y.Quo(y.Sub(x, floatOne), new(big.Float).Add(x, floatOne))
+	if y.Sign() == 0 {
+		return z
 	}
+	// |y| < 2**exp, and each term of the series gains 2|exp| bits.
+	exp := y.MantExp(nil)
+	if -exp*2*atanhTerms >= int(prec) {
+		return z.Set(floatAtanhSeries(c, y))
+	}
+	// The result is formed as the difference of two terms that can be
+	// much bigger than it, so work with enough extra bits to cover the loss.
+	extra := guardBits
+	if exp < 0 {
+		extra -= exp
+	}
+	return z.Set(floatLogAGM(x, prec+uint(extra)))
+}
 
-	// y = 1-x (whereupon x = 1-y and we use that in the series).
-	y := newFloat(c).SetInt64(1)
-	y.Sub(y, mantissa)
-
-	// The Maclaurin series for log(1-y) == log(x) is: -y - y²/2 - y³/3 ...
+// atanhTerms is the most terms floatLog sums in the atanh series
+// before it uses the arithmetic-geometric mean instead.
+const atanhTerms = 20
 
-	yN := newFloat(c).Set(y)
-	term := newFloat(c)
-	n := newFloat(c).Set(floatOne)
+// floatAtanhSeries returns 2 atanh y = 2(y + y³/3 + y⁵/5 + ...),
+// which is log((1+y)/(1-y)), for small y.
+func floatAtanhSeries(c Context, y *big.Float) *big.Float {
+	y2 := new(big.Float).SetPrec(y.Prec()).Mul(y, y)
+	yN := new(big.Float).SetPrec(y.Prec()).Set(y)
+	term := new(big.Float).SetPrec(y.Prec())
+	n := new(big.Float)
 	z := newFloat(c)
-
-	// This is the slowest-converging series, so we add a factor of ten to the cutoff.
-	// Only necessary when FloatPrec is at or beyond constPrecisionInBits.
-
-	for loop := newLoop(c.Config(), "log", x, 40); ; {
-		term.Quo(yN, n.SetUint64(loop.i+1))
-		z.Sub(z, term)
+	for loop := newLoop(c.Config(), "log", y, 1); ; {
+		term.Quo(yN, n.SetUint64(2*loop.i+1))
+		z.Add(z, term)
 		if loop.done(z) {
 			break
 		}
-		// Advance y**index (multiply by y).
-		yN.Mul(yN, y)
+		yN.Mul(yN, y2)
 	}
+	return z.Mul(z, floatTwo)
+}
 
-	if invert {
-		z.Neg(z)
+// floatLogAGM returns log x to prec bits using the arithmetic-geometric
+// mean: for s > 2**(prec/2), log s = π/(2 AGM(1, 4/s)) to within a
+// relative error of about 1/s². To get there, s = x·2**m, and the
+// result is log s - m log 2.
+func floatLogAGM(x *big.Float, prec uint) *big.Float {
+	m := int(prec/2) + 2 - x.MantExp(nil)
+	if m < 0 {
+		m = 0
+	}
+	s := new(big.Float).SetPrec(prec).SetMantExp(x, m)
+	a := new(big.Float).SetPrec(prec).SetInt64(1)
+	b := new(big.Float).SetPrec(prec).SetInt64(4)
+	b.Quo(b, s)
+	t := new(big.Float).SetPrec(prec)
+	diff := new(big.Float).SetPrec(prec)
+	for {
+		// a, b = (a+b)/2, sqrt(ab)
+		t.Mul(a, b)
+		a.Add(a, b)
+		a.SetMantExp(a, -1)
+		sqrtNewton(b, t)
+		// Stop when a and b agree to within rounding error, the last
+		// few bits, which the guard bits absorb.
+		if diff.Sub(a, b).Sign() == 0 || diff.MantExp(nil) < a.MantExp(nil)-int(prec)+8 {
+			break
+		}
 	}
-	z.Add(z, exp)
+	z := new(big.Float).SetPrec(prec).Quo(constPi.value(prec), a)
+	z.SetMantExp(z, -1)
+	t.SetInt64(int64(m))
+	t.Mul(t, constLog2.value(prec))
+	return z.Sub(z, t)
+}
+
+// sqrtNewton sets z to the square root of the positive x, to z's precision.
+// Unlike big.Float's Sqrt, which runs every Newton step at full precision,
+// it doubles the precision at each step, so it costs only a few
+// multiplications at full precision, which matters for floatLogAGM.
+func sqrtNewton(z, x *big.Float) *big.Float {
+	// x = mant·2**exp with exp even, so sqrt x = sqrt(mant)·2**(exp/2).
+	mant := new(big.Float)
+	exp := x.MantExp(mant)
+	if exp%2 != 0 {
+		mant.SetMantExp(mant, 1)
+		exp--
+	}
+	// The precisions of the steps, from the last down to that of float64.
+	var precs []uint
+	for p := z.Prec() + 32; p > 50; p = p/2 + 2 {
+		precs = append(precs, p)
+	}
+	// r converges to 1/sqrt(mant): r += r(1 - mant·r²)/2.
+	m, _ := mant.Float64()
+	r := new(big.Float).SetFloat64(1 / math.Sqrt(m))
+	t := new(big.Float)
+	for i := len(precs) - 1; i >= 0; i-- {
+		p := precs[i]
+		r.SetPrec(p)
+		t.SetPrec(p)
+		t.Mul(r, r)
+		t.Mul(t, mant)
+		t.Sub(floatOne, t)
+		t.Mul(t, r)
+		t.SetMantExp(t, -1)
+		r.Add(r, t)
+	}
+	z.Mul(mant, r)
+	return z.SetMantExp(z, exp/2)
+}
 
-	return z
+// powerOf2 returns k if x is 2**k.
+func powerOf2(x *big.Float) (int64, bool) {
+	mant := new(big.Float)
+	exp := x.MantExp(mant)
+	if mant.Cmp(floatHalf) != 0 {
+		return 0, false
+	}
+	return int64(exp - 1), true
+}
+
+// powerOf10 returns k if x is 10**k for positive k.
+// (Negative powers of 10 are not exact in binary.)
+func powerOf10(x *big.Float) (int64, bool) {
+	if !x.IsInt() || x.Cmp(floatOne) <= 0 {
+		return 0, false
+	}
+	// 10**k is 5**k·2**k, where 5**k has about 2.32k bits.
+	// Check that first, to avoid computing a huge 5**k.
+	bits := x.MinPrec()
+	k := x.MantExp(nil) - int(bits)
+	if k <= 0 || math.Abs(float64(bits)-float64(k)*math.Log2(5)) > 2 {
+		return 0, false
+	}
+	five := new(big.Int).Exp(big.NewInt(5), big.NewInt(int64(k)), nil)
+	mant, _ := new(big.Float).SetMantExp(x, -k).Int(nil)
+	if mant.Cmp(five) != 0 {
+		return 0, false
+	}
+	return int64(k), true
 }
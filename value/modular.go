@@ -0,0 +1,84 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import "math/big"
+
+// modularBinary evaluates the arithmetic operator op on the integers u and v
+// modulo m, returning a result in [0, m). It returns nil if the operator
+// or the operands are not subject to modular arithmetic, and the operation
+// should proceed normally.
+func modularBinary(c Context, u Value, op string, v Value, m *big.Int) Value {
+	switch op {
+	case "+", "-", "*", "/", "**":
+	default:
+		return nil
+	}
+	x, ok := modularInt(u)
+	if !ok {
+		return nil
+	}
+	y, ok := modularInt(v)
+	if !ok {
+		return nil
+	}
+	z := new(big.Int)
+	switch op {
+	case "+":
+		z.Add(x, y)
+	case "-":
+		z.Sub(x, y)
+	case "*":
+		z.Mul(x, y)
+	case "/":
+		// Multiply by the inverse.
+		if z.ModInverse(y.Mod(y, m), m) == nil {
+			Errorf("%s has no inverse modulo %s", v.Sprint(c.Config()), m)
+		}
+		z.Mul(x, z)
+	case "**":
+		x.Mod(x, m)
+		if y.Sign() < 0 {
+			if x.ModInverse(x, m) == nil {
+				Errorf("%s has no inverse modulo %s", u.Sprint(c.Config()), m)
+			}
+			y.Neg(y)
+		}
+		z.Exp(x, y, m)
+	}
+	return BigInt{z.Mod(z, m)}.shrink()
+}
+
+// modularUnary evaluates the unary operator op, negation or reciprocal,
+// on the integer v modulo m. Like modularBinary, it returns nil if the
+// operator or the operand are not subject to modular arithmetic.
+func modularUnary(c Context, op string, v Value, m *big.Int) Value {
+	x, ok := modularInt(v)
+	if !ok {
+		return nil
+	}
+	switch op {
+	case "-":
+		x.Neg(x)
+	case "/":
+		if x.ModInverse(x.Mod(x, m), m) == nil {
+			Errorf("%s has no inverse modulo %s", v.Sprint(c.Config()), m)
+		}
+	default:
+		return nil
+	}
+	return BigInt{x.Mod(x, m)}.shrink()
+}
+
+// modularInt returns a copy of the value of v, if it is an integer.
+func modularInt(v Value) (*big.Int, bool) {
+	switch v := v.(type) {
+	case Int:
+		return big.NewInt(int64(v)), true
+	case BigInt:
+		return new(big.Int).Set(v.Int), true
+	}
+	return nil, false
+}
@@ -0,0 +1,316 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+// Number theory: primes, factorization, and modular arithmetic on integers.
+
+import (
+	"math/big"
+	"sort"
+)
+
+// bigIntOf returns the value of v, an Int or BigInt, as a *big.Int.
+// The result must not be modified.
+func bigIntOf(v Value) *big.Int {
+	switch v := v.(type) {
+	case Int:
+		return big.NewInt(int64(v))
+	case BigInt:
+		return v.Int
+	}
+	Errorf("internal error: bigIntOf of %T", v)
+	panic("not reached")
+}
+
+// isPrime reports whether x is prime. The test is Baillie-PSW plus
+// Miller-Rabin rounds, which is exact for x < 2**64.
+func isPrime(x *big.Int) bool {
+	return x.Sign() > 0 && x.ProbablyPrime(20)
+}
+
+func isprime(c Context, v Value) Value {
+	return toInt(isPrime(bigIntOf(v)))
+}
+
+// nextprime returns the smallest prime greater than v.
+func nextprime(c Context, v Value) Value {
+	x := bigIntOf(v)
+	if x.Cmp(bigTwo.Int) < 0 {
+		return Int(2)
+	}
+	z := new(big.Int).Add(x, bigOne.Int)
+	if z.Bit(0) == 0 && z.Cmp(bigTwo.Int) != 0 {
+		z.Add(z, bigOne.Int)
+	}
+	for !isPrime(z) {
+		z.Add(z, bigTwo.Int)
+	}
+	return BigInt{z}.shrink()
+}
+
+// factor returns a vector of the prime factors of v, in increasing order,
+// each repeated according to its multiplicity.
+func factor(c Context, v Value) Value {
+	x := bigIntOf(v)
+	if x.Sign() <= 0 {
+		Errorf("factor of non-positive value %s", x)
+	}
+	factors := primeFactors(x)
+	elems := make([]Value, len(factors))
+	for i, f := range factors {
+		elems[i] = BigInt{f}.shrink()
+	}
+	return NewVector(elems)
+}
+
+// factorVector returns the prime factors of each element of the vector v,
+// one row of a matrix per element. Since vectors cannot nest, the rows are
+// padded at the end with 1s, which leave the product of each row unchanged.
+func factorVector(c Context, v Value) Value {
+	vec := v.(Vector)
+	rows := make([]Vector, len(vec))
+	width := 0
+	for i, x := range vec {
+		if whichType(x) != intType && whichType(x) != bigIntType {
+			Errorf("factor of non-integer %s", x.Sprint(c.Config()))
+		}
+		rows[i] = factor(c, x).(Vector)
+		if len(rows[i]) > width {
+			width = len(rows[i])
+		}
+	}
+	data := make([]Value, 0, len(vec)*width)
+	for _, row := range rows {
+		data = append(data, row...)
+		for j := len(row); j < width; j++ {
+			data = append(data, one)
+		}
+	}
+	return NewMatrix([]int{len(vec), width}, data)
+}
+
+// primeFactors returns the prime factors of x > 0 in increasing order.
+func primeFactors(x *big.Int) []*big.Int {
+	var factors []*big.Int
+	n := new(big.Int).Set(x)
+	// Trial division removes the small factors cheaply.
+	d, q, r := new(big.Int), new(big.Int), new(big.Int)
+	for i := int64(2); i < 1000; i++ {
+		d.SetInt64(i)
+		for {
+			q.QuoRem(n, d, r)
+			if r.Sign() != 0 {
+				break
+			}
+			factors = append(factors, big.NewInt(i))
+			n.Set(q)
+		}
+		if q.Cmp(d) < 0 {
+			// n has no factor > i other than itself.
+			break
+		}
+	}
+	if n.Cmp(bigOne.Int) > 0 {
+		factors = append(factors, splitFactors(n)...)
+	}
+	sort.Slice(factors, func(i, j int) bool {
+		return factors[i].Cmp(factors[j]) < 0
+	})
+	return factors
+}
+
+// splitFactors returns the prime factors, in no particular order,
+// of n > 1, which has no small factors.
+func splitFactors(n *big.Int) []*big.Int {
+	if isPrime(n) {
+		return []*big.Int{n}
+	}
+	if r := bigIntRoot(n, 2); new(big.Int).Mul(r, r).Cmp(n) == 0 {
+		f := splitFactors(r)
+		return append(f, f...)
+	}
+	d := pollardRho(n)
+	return append(splitFactors(d), splitFactors(new(big.Int).Quo(n, d))...)
+}
+
+// pollardRho returns a non-trivial factor of the composite n,
+// using Brent's variant of Pollard's rho method.
+func pollardRho(n *big.Int) *big.Int {
+	const m = 128 // Steps between gcd computations.
+	x, y, ys := new(big.Int), new(big.Int), new(big.Int)
+	q, g, t := new(big.Int), new(big.Int), new(big.Int)
+	for inc := int64(1); ; inc++ {
+		a := big.NewInt(inc)
+		// f(x) = x² + a mod n.
+		f := func(z *big.Int) {
+			z.Mul(z, z)
+			z.Add(z, a)
+			z.Mod(z, n)
+		}
+		y.SetInt64(2)
+		q.SetInt64(1)
+		g.SetInt64(1)
+		for r := 1; g.Cmp(bigOne.Int) == 0; r *= 2 {
+			x.Set(y)
+			for i := 0; i < r; i++ {
+				f(y)
+			}
+			for k := 0; k < r && g.Cmp(bigOne.Int) == 0; k += m {
+				ys.Set(y)
+				for i := 0; i < m && i < r-k; i++ {
+					f(y)
+					q.Mul(q, t.Abs(t.Sub(x, y)))
+					q.Mod(q, n)
+				}
+				g.GCD(nil, nil, q, n)
+			}
+		}
+		if g.Cmp(n) == 0 {
+			// The batch overshot; backtrack one step at a time.
+			for {
+				f(ys)
+				g.GCD(nil, nil, t.Abs(t.Sub(x, ys)), n)
+				if g.Cmp(bigOne.Int) > 0 {
+					break
+				}
+			}
+		}
+		if g.Cmp(n) != 0 {
+			return new(big.Int).Set(g)
+		}
+		// Failed; try another polynomial.
+	}
+}
+
+// totient returns Euler's totient function of v, the number of
+// integers in [1, v] relatively prime to v.
+func totient(c Context, v Value) Value {
+	x := bigIntOf(v)
+	if x.Sign() <= 0 {
+		Errorf("totient of non-positive value %s", x)
+	}
+	// φ(n) = n Π (1 - 1/p) over the distinct primes p dividing n.
+	z := new(big.Int).Set(x)
+	var prev *big.Int
+	for _, p := range primeFactors(x) {
+		if prev != nil && p.Cmp(prev) == 0 {
+			continue
+		}
+		z.Quo(z, p)
+		z.Mul(z, new(big.Int).Sub(p, bigOne.Int))
+		prev = p
+	}
+	return BigInt{z}.shrink()
+}
+
+// isqrt returns the integer square root of v, the floor of sqrt v.
+func isqrt(c Context, v Value) Value {
+	x := bigIntOf(v)
+	if x.Sign() < 0 {
+		Errorf("isqrt of negative value %s", x)
+	}
+	return BigInt{new(big.Int).Sqrt(x)}.shrink()
+}
+
+// iroot returns the integer u'th root of v, truncated towards zero.
+func iroot(c Context, u, v Value) Value {
+	n := bigIntOf(u)
+	if n.Sign() <= 0 || !n.IsInt64() || n.Int64() > maxInt {
+		Errorf("iroot: bad degree %s", n)
+	}
+	x := bigIntOf(v)
+	if x.Sign() >= 0 {
+		return BigInt{bigIntRoot(x, uint(n.Int64()))}.shrink()
+	}
+	if n.Bit(0) == 0 {
+		Errorf("iroot: even root of negative value %s", x)
+	}
+	z := bigIntRoot(new(big.Int).Neg(x), uint(n.Int64()))
+	return BigInt{z.Neg(z)}.shrink()
+}
+
+// gcd returns the greatest common divisor of u and v, which is never negative.
+func gcd(c Context, u, v Value) Value {
+	return BigInt{new(big.Int).GCD(nil, nil, bigIntOf(u), bigIntOf(v))}.shrink()
+}
+
+// lcm returns the least common multiple of u and v, which is never negative.
+func lcm(c Context, u, v Value) Value {
+	x, y := bigIntOf(u), bigIntOf(v)
+	if x.Sign() == 0 || y.Sign() == 0 {
+		return zero
+	}
+	z := new(big.Int).GCD(nil, nil, x, y)
+	z.Quo(x, z)
+	z.Mul(z, y)
+	return BigInt{z.Abs(z)}.shrink()
+}
+
+// modulus returns v as a modulus, which must be positive.
+func modulus(name string, v Value) *big.Int {
+	m := bigIntOf(v)
+	if m.Sign() <= 0 {
+		Errorf("%s: non-positive modulus %s", name, m)
+	}
+	return m
+}
+
+// modinv returns the inverse of u modulo v.
+func modinv(c Context, u, v Value) Value {
+	m := modulus("modinv", v)
+	x := new(big.Int).Mod(bigIntOf(u), m)
+	if x.ModInverse(x, m) == nil {
+		Errorf("modinv: %s has no inverse modulo %s", bigIntOf(u), m)
+	}
+	return BigInt{x}.shrink()
+}
+
+// powmod returns, for each element b of u, b to the power e modulo m,
+// where v is the vector e m.
+func powmod(c Context, u, v Value) Value {
+	bases, args := u.(Vector), v.(Vector)
+	if len(args) != 2 {
+		Errorf("powmod: right operand must be exponent and modulus")
+	}
+	for _, a := range args {
+		if _, ok := a.(Int); !ok {
+			if _, ok := a.(BigInt); !ok {
+				Errorf("powmod: non-integer %s", a.Sprint(c.Config()))
+			}
+		}
+	}
+	exp, m := bigIntOf(args[0]), modulus("powmod", args[1])
+	elems := make([]Value, len(bases))
+	pfor(true, 1, len(bases), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			b := bases[i]
+			switch b.(type) {
+			case Int, BigInt:
+			default:
+				Errorf("powmod: non-integer %s", b.Sprint(c.Config()))
+			}
+			x := new(big.Int).Mod(bigIntOf(b), m)
+			e := exp
+			if e.Sign() < 0 {
+				if x.ModInverse(x, m) == nil {
+					Errorf("powmod: %s has no inverse modulo %s", bigIntOf(b), m)
+				}
+				e = new(big.Int).Neg(e)
+			}
+			x.Exp(x, e, m)
+			elems[i] = BigInt{x.Mod(x, m)}.shrink() // Exp gives 1 for x**0 even if m is 1.
+		}
+	})
+	return NewVector(elems)
+}
+
+// jacobi returns the Jacobi symbol (u/v).
+func jacobi(c Context, u, v Value) Value {
+	y := bigIntOf(v)
+	if y.Sign() <= 0 || y.Bit(0) == 0 {
+		Errorf("jacobi: %s is not a positive odd integer", y)
+	}
+	return Int(big.Jacobi(bigIntOf(u), y))
+}
@@ -0,0 +1,311 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+// Binary encoding of values for workspace snapshots.
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+	"math/big"
+	"unicode/utf8"
+)
+
+/*
+Each value is a tag byte followed by its payload. Integers are varints,
+big numbers use the exact encodings of their GobEncode methods, which for
+floats include the precision and the full mantissa, and float64s are their
+IEEE 754 bits. A vector is its length followed by its elements; a matrix
+is its rank, its shape and then its data as a vector.
+*/
+
+// Tags identifying the types of encoded values.
+const (
+	snapInt byte = iota + 1
+	snapChar
+	snapBigInt
+	snapBigRat
+	snapFloat
+	snapBigFloat
+	snapInterval
+	snapComplex
+	snapVector
+	snapMatrix
+)
+
+// A SnapshotWriter writes values and other data in binary.
+// Write errors are sticky and reported by Flush.
+type SnapshotWriter struct {
+	w   *bufio.Writer
+	buf [binary.MaxVarintLen64]byte
+}
+
+// NewSnapshotWriter returns a SnapshotWriter that writes to w.
+func NewSnapshotWriter(w io.Writer) *SnapshotWriter {
+	return &SnapshotWriter{w: bufio.NewWriter(w)}
+}
+
+// Flush writes any buffered data and returns the first error, if any.
+func (s *SnapshotWriter) Flush() error {
+	return s.w.Flush()
+}
+
+// Uvarint writes the unsigned integer x.
+func (s *SnapshotWriter) Uvarint(x uint64) {
+	n := binary.PutUvarint(s.buf[:], x)
+	s.w.Write(s.buf[:n])
+}
+
+// Varint writes the signed integer x.
+func (s *SnapshotWriter) Varint(x int64) {
+	n := binary.PutVarint(s.buf[:], x)
+	s.w.Write(s.buf[:n])
+}
+
+// Bytes writes the length of b and then b.
+func (s *SnapshotWriter) Bytes(b []byte) {
+	s.Uvarint(uint64(len(b)))
+	s.w.Write(b)
+}
+
+// String writes the length of str and then str.
+func (s *SnapshotWriter) String(str string) {
+	s.Uvarint(uint64(len(str)))
+	s.w.WriteString(str)
+}
+
+// gob writes the exact encoding of a big number.
+func (s *SnapshotWriter) gob(x interface{ GobEncode() ([]byte, error) }) {
+	b, err := x.GobEncode()
+	if err != nil {
+		Errorf("snapshot: %s", err)
+	}
+	s.Bytes(b)
+}
+
+// BigInt writes x, which may be nil.
+func (s *SnapshotWriter) BigInt(x *big.Int) {
+	if x == nil {
+		s.Bytes(nil)
+		return
+	}
+	s.gob(x)
+}
+
+// Value writes v.
+func (s *SnapshotWriter) Value(v Value) {
+	switch v := v.(type) {
+	case Int:
+		s.w.WriteByte(snapInt)
+		s.Varint(int64(v))
+	case Char:
+		s.w.WriteByte(snapChar)
+		s.Uvarint(uint64(v))
+	case BigInt:
+		s.w.WriteByte(snapBigInt)
+		s.gob(v.Int)
+	case BigRat:
+		s.w.WriteByte(snapBigRat)
+		s.gob(v.Rat)
+	case Float:
+		s.w.WriteByte(snapFloat)
+		s.Uvarint(math.Float64bits(float64(v)))
+	case BigFloat:
+		s.w.WriteByte(snapBigFloat)
+		s.gob(v.Float)
+	case Interval:
+		s.w.WriteByte(snapInterval)
+		s.gob(v.lo)
+		s.gob(v.hi)
+	case Complex:
+		s.w.WriteByte(snapComplex)
+		s.Value(v.real)
+		s.Value(v.imag)
+	case Vector:
+		s.w.WriteByte(snapVector)
+		s.elems(v)
+	case Complex128, Int64Vector, Float64Vector:
+		s.Value(Boxed(v))
+	case *Matrix:
+		s.w.WriteByte(snapMatrix)
+		s.Uvarint(uint64(len(v.shape)))
+		for _, d := range v.shape {
+			s.Uvarint(uint64(d))
+		}
+		s.elems(v.data)
+	default:
+		Errorf("snapshot: cannot save type %T", v)
+	}
+}
+
+// elems writes the length of v and then its elements.
+func (s *SnapshotWriter) elems(v Vector) {
+	s.Uvarint(uint64(len(v)))
+	for _, e := range v {
+		s.Value(e)
+	}
+}
+
+// A SnapshotReader reads data written by a SnapshotWriter.
+// It reports errors, including a truncated input, by calling Errorf.
+type SnapshotReader struct {
+	r    *bufio.Reader
+	name string
+}
+
+// NewSnapshotReader returns a SnapshotReader that reads from r.
+// The name identifies the input in errors.
+func NewSnapshotReader(r io.Reader, name string) *SnapshotReader {
+	return &SnapshotReader{r: bufio.NewReader(r), name: name}
+}
+
+func (s *SnapshotReader) check(err error) {
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		Errorf("restore: %s: %s", s.name, err)
+	}
+}
+
+func (s *SnapshotReader) corrupt() {
+	Errorf("restore: %s: corrupt snapshot", s.name)
+}
+
+// Uvarint reads an unsigned integer.
+func (s *SnapshotReader) Uvarint() uint64 {
+	x, err := binary.ReadUvarint(s.r)
+	s.check(err)
+	return x
+}
+
+// Varint reads a signed integer.
+func (s *SnapshotReader) Varint() int64 {
+	x, err := binary.ReadVarint(s.r)
+	s.check(err)
+	return x
+}
+
+// length reads a length, and checks it fits in an int.
+func (s *SnapshotReader) length() int {
+	n := s.Uvarint()
+	if n > maxInt {
+		s.corrupt()
+	}
+	return int(n)
+}
+
+// Bytes reads a length and then that many bytes.
+func (s *SnapshotReader) Bytes() []byte {
+	n := s.length()
+	// Read rather than allocate, so a corrupt length cannot force a huge allocation.
+	b, err := io.ReadAll(io.LimitReader(s.r, int64(n)))
+	s.check(err)
+	if len(b) != n {
+		s.check(io.ErrUnexpectedEOF)
+	}
+	return b
+}
+
+// String reads a length and then that many bytes, as a string.
+func (s *SnapshotReader) String() string {
+	return string(s.Bytes())
+}
+
+// gob reads the exact encoding of a big number into x.
+func (s *SnapshotReader) gob(x interface{ GobDecode([]byte) error }) {
+	if err := x.GobDecode(s.Bytes()); err != nil {
+		s.corrupt()
+	}
+}
+
+// BigInt reads a big integer, which may be nil.
+func (s *SnapshotReader) BigInt() *big.Int {
+	b := s.Bytes()
+	if len(b) == 0 {
+		return nil
+	}
+	x := new(big.Int)
+	if err := x.GobDecode(b); err != nil {
+		s.corrupt()
+	}
+	return x
+}
+
+// Value reads a value.
+func (s *SnapshotReader) Value() Value {
+	tag, err := s.r.ReadByte()
+	s.check(err)
+	switch tag {
+	case snapInt:
+		return Int(s.Varint())
+	case snapChar:
+		r := s.Uvarint()
+		if r > utf8.MaxRune || !utf8.ValidRune(rune(r)) {
+			s.corrupt()
+		}
+		return Char(r)
+	case snapBigInt:
+		x := new(big.Int)
+		s.gob(x)
+		return BigInt{x}
+	case snapBigRat:
+		x := new(big.Rat)
+		s.gob(x)
+		return BigRat{x}
+	case snapFloat:
+		return Float(math.Float64frombits(s.Uvarint()))
+	case snapBigFloat:
+		x := new(big.Float)
+		s.gob(x)
+		return BigFloat{x}
+	case snapInterval:
+		lo, hi := new(big.Float), new(big.Float)
+		s.gob(lo)
+		s.gob(hi)
+		return Interval{lo, hi}
+	case snapComplex:
+		re := s.Value()
+		im := s.Value()
+		return Complex{re, im}
+	case snapVector:
+		return s.elems()
+	case snapMatrix:
+		rank := s.length()
+		shape := make([]int, rank)
+		size := 1
+		for i := range shape {
+			shape[i] = s.length()
+			if shape[i] > 0 && size > maxInt/shape[i] {
+				s.corrupt()
+			}
+			size *= shape[i]
+		}
+		data := s.elems()
+		if len(data) != size {
+			s.corrupt()
+		}
+		return &Matrix{shape: shape, data: data}
+	}
+	s.corrupt()
+	panic("not reached")
+}
+
+// elems reads a length and then that many values.
+func (s *SnapshotReader) elems() Vector {
+	n := s.length()
+	// Grow as needed, as in Bytes.
+	c := n
+	if c > 1<<20 {
+		c = 1 << 20
+	}
+	v := make(Vector, 0, c)
+	for i := 0; i < n; i++ {
+		v = append(v, s.Value())
+	}
+	return v
+}
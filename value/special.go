@@ -0,0 +1,573 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file implements the special functions: gamma, log gamma and beta,
+// the error functions, the Riemann zeta function, and Bessel functions of
+// the first and second kinds. Most are written in terms of ivy's own
+// arithmetic on values so the same code serves real and complex arguments.
+
+package value
+
+import (
+	"math"
+	"math/big"
+	"sync"
+
+	"robpike.io/ivy/config"
+)
+
+// floatValue converts v, which may be complex, to floating point.
+func floatValue(c Context, v Value) Value {
+	if z, ok := v.(Complex); ok {
+		return Complex{floatSelf(c, z.real), floatSelf(c, z.imag)}
+	}
+	return floatSelf(c, v)
+}
+
+// parts returns the real and imaginary parts of v as floats.
+func parts(c Context, v Value) (re, im *big.Float) {
+	if z, ok := v.(Complex); ok {
+		return floatSelf(c, z.real).(BigFloat).Float, floatSelf(c, z.imag).(BigFloat).Float
+	}
+	return floatSelf(c, v).(BigFloat).Float, floatZero
+}
+
+// isReal reports whether v is not complex.
+func isReal(v Value) bool {
+	_, ok := v.(Complex)
+	return !ok
+}
+
+// nonPositiveInteger reports whether v is a real integer <= 0,
+// a pole of the gamma function.
+func nonPositiveInteger(c Context, v Value) bool {
+	if !isReal(v) {
+		return false
+	}
+	f := floatSelf(c, v).(BigFloat).Float
+	return f.Sign() <= 0 && f.IsInt()
+}
+
+// valueLoop is a loop checker for a series whose sum may be complex.
+// It runs a loop on each part.
+type valueLoop struct {
+	i      uint64 // Loop count.
+	re, im *loop
+}
+
+func newValueLoop(c Context, name string, x Value, itersPerBit uint) *valueLoop {
+	re, im := parts(c, x)
+	return &valueLoop{
+		re: newLoop(c.Config(), name, re, itersPerBit),
+		im: newLoop(c.Config(), name, im, itersPerBit),
+	}
+}
+
+// done reports whether both parts of z have converged.
+func (l *valueLoop) done(c Context, z Value) bool {
+	re, im := parts(c, z)
+	reDone := l.re.done(re)
+	imDone := l.im.done(im)
+	if reDone && imDone {
+		return true
+	}
+	l.i++
+	return false
+}
+
+// bernoulliCache holds the Bernoulli numbers computed so far.
+var bernoulliCache struct {
+	sync.Mutex
+	b []*big.Rat
+}
+
+// bernoulli returns the Bernoulli number B(n), with B(1) = -1/2.
+func bernoulli(n int) *big.Rat {
+	bernoulliCache.Lock()
+	defer bernoulliCache.Unlock()
+	b := bernoulliCache.b
+	if b == nil {
+		b = []*big.Rat{big.NewRat(1, 1)}
+	}
+	// B(m) = -1/(m+1) Σ_{k<m} binomial(m+1, k) B(k).
+	for m := len(b); m <= n; m++ {
+		sum := new(big.Rat)
+		binom := big.NewInt(1)
+		term := new(big.Rat)
+		for k := 0; k < m; k++ {
+			if b[k].Sign() != 0 {
+				sum.Add(sum, term.Mul(term.SetInt(binom), b[k]))
+			}
+			binom.Mul(binom, big.NewInt(int64(m+1-k)))
+			binom.Quo(binom, big.NewInt(int64(k+1)))
+		}
+		b = append(b, sum.Quo(sum, big.NewRat(-int64(m+1), 1)))
+	}
+	bernoulliCache.b = b
+	return b[n]
+}
+
+// gamma returns Γ(v). For positive integers it is exact.
+func gamma(c Context, v Value) Value {
+	if i, ok := v.(Int); ok {
+		if i <= 0 {
+			Errorf("gamma of non-positive integer %d", i)
+		}
+		return BigInt{factorial(int64(i) - 1)}.shrink()
+	}
+	if nonPositiveInteger(c, v) {
+		Errorf("gamma of non-positive integer %s", v.Sprint(c.Config()))
+	}
+	z := floatValue(c, v)
+	if re, _ := parts(c, z); re.Cmp(floatHalf) < 0 {
+		// Reflection: Γ(z)Γ(1-z) = π/sin(πz).
+		pi := BigFloat{floatPi}
+		s := c.EvalUnary("sin", c.EvalBinary(pi, "*", z))
+		return c.EvalBinary(pi, "/", c.EvalBinary(s, "*", gamma(c, c.EvalBinary(one, "-", z))))
+	}
+	return c.EvalUnary("**", stirling(c, z))
+}
+
+// lgamma returns the natural logarithm of |Γ(v)| for real v,
+// and the principal branch of log Γ(v) for complex v.
+func lgamma(c Context, v Value) Value {
+	if i, ok := v.(Int); ok {
+		if i <= 0 {
+			Errorf("lgamma of non-positive integer %d", i)
+		}
+		return logn(c, BigInt{factorial(int64(i) - 1)}.shrink())
+	}
+	if nonPositiveInteger(c, v) {
+		Errorf("lgamma of non-positive integer %s", v.Sprint(c.Config()))
+	}
+	z := floatValue(c, v)
+	if re, _ := parts(c, z); re.Cmp(floatHalf) < 0 {
+		// Reflection: log Γ(z) = log π - log sin(πz) - log Γ(1-z).
+		pi := BigFloat{floatPi}
+		s := c.EvalUnary("sin", c.EvalBinary(pi, "*", z))
+		if isReal(z) {
+			s = c.EvalUnary("abs", s)
+		}
+		lg := c.EvalBinary(c.EvalUnary("log", pi), "-", c.EvalUnary("log", s))
+		return c.EvalBinary(lg, "-", lgamma(c, c.EvalBinary(one, "-", z)))
+	}
+	return stirling(c, z)
+}
+
+// stirling returns log Γ(z) for Re z >= 1/2. It uses the recurrence
+// Γ(z) = Γ(z+n)/(z(z+1)...(z+n-1)) to move the argument far enough from
+// the origin that Stirling's series
+//
+//	log Γ(w) = (w-½) log w - w + ½ log 2π + Σ B(2k)/(2k(2k-1)w**(2k-1))
+//
+// converges to the configured precision.
+func stirling(c Context, z Value) Value {
+	re, _ := parts(c, z)
+	n := int64(c.Config().FloatPrec()/2) + 10
+	fre, _ := re.Int64()
+	var prod Value = one
+	arg := 0.0 // Sum of the arguments of the factors, to choose the branch of the log.
+	w := z
+	for i := fre; i < n; i++ {
+		prod = c.EvalBinary(prod, "*", w)
+		arg += phase64(c, w)
+		w = c.EvalBinary(w, "+", one)
+	}
+	logProd := c.EvalUnary("log", prod)
+	if !isReal(logProd) {
+		// The log of the product may be on a different branch
+		// from the sum of the logs of the factors.
+		k := math.Round((arg - phase64(c, prod)) / (2 * math.Pi))
+		twoPiK := BigFloat{newFloat(c).Mul(floatPi, newFloat(c).SetFloat64(2*k))}
+		logProd = c.EvalBinary(logProd, "+", newComplexImag(twoPiK))
+	}
+
+	halfLog2Pi := newFloat(c).Mul(floatPi, floatTwo)
+	halfLog2Pi = floatLog(c, halfLog2Pi)
+	halfLog2Pi.Quo(halfLog2Pi, floatTwo)
+	s := c.EvalBinary(c.EvalBinary(w, "-", BigFloat{floatHalf}), "*", c.EvalUnary("log", w))
+	s = c.EvalBinary(s, "-", w)
+	s = c.EvalBinary(s, "+", BigFloat{halfLog2Pi})
+
+	wSquared := c.EvalBinary(w, "*", w)
+	wPower := w
+	for loop := newValueLoop(c, "lgamma", z, 1); ; {
+		k := int64(loop.i + 1)
+		coef := new(big.Rat).Quo(bernoulli(int(2*k)), big.NewRat(2*k*(2*k-1), 1))
+		s = c.EvalBinary(s, "+", c.EvalBinary(BigRat{coef}, "/", wPower))
+		if loop.done(c, s) {
+			break
+		}
+		wPower = c.EvalBinary(wPower, "*", wSquared)
+	}
+	return c.EvalBinary(s, "-", logProd)
+}
+
+// phase64 returns an approximation to the phase of v, which must be nonzero.
+func phase64(c Context, v Value) float64 {
+	re, im := parts(c, v)
+	// Scale the parts to avoid overflow in float64.
+	exp := re.MantExp(nil)
+	if e := im.MantExp(nil); im.Sign() != 0 && (re.Sign() == 0 || e > exp) {
+		exp = e
+	}
+	x, _ := new(big.Float).SetMantExp(re, -exp).Float64()
+	y, _ := new(big.Float).SetMantExp(im, -exp).Float64()
+	return math.Atan2(y, x)
+}
+
+// beta returns B(u, v) = Γ(u)Γ(v)/Γ(u+v).
+func beta(c Context, u, v Value) Value {
+	num := c.EvalBinary(gamma(c, u), "*", gamma(c, v))
+	return c.EvalBinary(num, "/", gamma(c, c.EvalBinary(u, "+", v)))
+}
+
+// erf returns the error function of v.
+func erf(c Context, v Value) Value {
+	if isReal(v) {
+		return BigFloat{floatErf(c, floatSelf(c, v).(BigFloat).Float, false)}.shrink()
+	}
+	return erfSeries(c, floatValue(c, v))
+}
+
+// erfc returns the complementary error function of v, 1 - erf v.
+func erfc(c Context, v Value) Value {
+	if isReal(v) {
+		return BigFloat{floatErf(c, floatSelf(c, v).(BigFloat).Float, true)}.shrink()
+	}
+	return c.EvalBinary(one, "-", erfSeries(c, floatValue(c, v)))
+}
+
+// erfSeries returns erf z using the series
+//
+//	erf z = 2z/√π e**-z² Σ (2z²)**n / (1·3·5...(2n+1))
+func erfSeries(c Context, z Value) Value {
+	twoZ2 := c.EvalBinary(Int(2), "*", c.EvalBinary(z, "*", z))
+	var term, sum Value = one, one
+	for loop := newValueLoop(c, "erf", z, 4); ; {
+		term = c.EvalBinary(term, "*", twoZ2)
+		term = c.EvalBinary(term, "/", Int(2*loop.i+3))
+		sum = c.EvalBinary(sum, "+", term)
+		if loop.done(c, sum) {
+			break
+		}
+	}
+	sqrtPi := BigFloat{floatSqrt(c, floatPi)}
+	sum = c.EvalBinary(sum, "*", c.EvalBinary(c.EvalBinary(Int(2), "*", z), "/", sqrtPi))
+	return c.EvalBinary(sum, "*", c.EvalUnary("**", c.EvalUnary("-", c.EvalBinary(z, "*", z))))
+}
+
+// floatErf returns erf x, or erfc x if complement is set. Subtraction from 1
+// loses bits when x is large, so the computation uses extra precision.
+func floatErf(c Context, x *big.Float, complement bool) *big.Float {
+	conf := c.Config()
+	prec := conf.FloatPrec()
+	neg := x.Sign() < 0
+	ax := new(big.Float).Abs(x)
+	x2, _ := new(big.Float).Mul(ax, ax).Float64()
+	z := newFloat(c)
+	switch {
+	case x2 > float64(prec)/4:
+		// Large x: the continued fraction for erfc converges quickly.
+		z.Set(erfcFraction(c, ax, prec+32))
+		if !complement {
+			z.Sub(floatOne, z)
+			if neg {
+				z.Neg(z)
+			}
+		} else if neg {
+			z.Sub(floatTwo, z)
+		}
+	case complement && !neg:
+		// erfc x is about e**-x², so 1 - erf x cancels about x² log2 e bits.
+		z.Sub(floatOne, erfFloatSeries(c, ax, prec+uint(x2*math.Log2E)+32))
+	default:
+		z.Set(erfFloatSeries(c, ax, prec+32))
+		if neg {
+			z.Neg(z)
+		}
+		if complement {
+			z.Sub(floatOne, z)
+		}
+	}
+	return z
+}
+
+// erfFloatSeries computes erf x for x >= 0 with prec bits of precision.
+// All the terms of the series are positive, so there is no cancellation.
+func erfFloatSeries(c Context, x *big.Float, prec uint) *big.Float {
+	conf := *c.Config()
+	conf.SetFloatPrec(prec)
+	x2 := newF(&conf).Mul(x, x)
+	twoX2 := newF(&conf).Mul(x2, floatTwo)
+	term := newF(&conf).SetInt64(1)
+	sum := newF(&conf).SetInt64(1)
+	den := newF(&conf)
+	for loop := newLoop(&conf, "erf", x, 4); ; {
+		term.Mul(term, twoX2)
+		term.Quo(term, den.SetUint64(2*loop.i+3))
+		sum.Add(sum, term)
+		if loop.done(sum) {
+			break
+		}
+	}
+	sum.Mul(sum, x)
+	sum.Mul(sum, floatTwo)
+	sum.Quo(sum, newF(&conf).Sqrt(constPi.value(prec)))
+	return sum.Quo(sum, floatExp(&conf, x2))
+}
+
+// erfcFraction computes erfc x for large positive x with prec bits of
+// precision, evaluating the continued fraction
+//
+//	√π e**x² erfc x = 1/(x+ (1/2)/(x+ 1/(x+ (3/2)/(x+ 2/(x+ ...)))))
+//
+// by Lentz's method.
+func erfcFraction(c Context, x *big.Float, prec uint) *big.Float {
+	conf := *c.Config()
+	conf.SetFloatPrec(prec)
+	f := newF(&conf).Set(x)
+	C := newF(&conf).Set(x)
+	D := newF(&conf)
+	a := newF(&conf)
+	t := newF(&conf)
+	for loop := newLoop(&conf, "erfc", x, 4); ; {
+		a.SetUint64(loop.i + 1)
+		a.Quo(a, floatTwo)
+		// D = 1/(x + a·D), C = x + a/C, f = f·C·D.
+		D.Mul(D, a)
+		D.Add(D, x)
+		D.Quo(floatOne, D)
+		C.Quo(a, C)
+		C.Add(C, x)
+		f.Mul(f, t.Mul(C, D))
+		if loop.done(f) {
+			break
+		}
+	}
+	f.Mul(f, newF(&conf).Sqrt(constPi.value(prec)))
+	f.Mul(f, floatExp(&conf, t.Mul(x, x)))
+	return f.Quo(floatOne, f)
+}
+
+// floatExp returns e**x for x >= 0. To keep the Taylor series short,
+// the argument is halved until it is less than 1 and the result squared
+// back up, with extra precision to cover the squarings.
+func floatExp(conf *config.Config, x *big.Float) *big.Float {
+	halvings := x.MantExp(nil)
+	if halvings <= 0 {
+		return exponential(conf, x)
+	}
+	wconf := *conf
+	wconf.SetFloatPrec(conf.FloatPrec() + uint(halvings))
+	y := newF(&wconf).SetMantExp(x, -halvings)
+	z := exponential(&wconf, y)
+	for i := 0; i < halvings; i++ {
+		z.Mul(z, z)
+	}
+	return newF(conf).Set(z)
+}
+
+// zeta returns the Riemann zeta function of v. It is exact for
+// non-positive integers.
+func zeta(c Context, v Value) Value {
+	if i, ok := v.(Int); ok {
+		switch {
+		case i == 1:
+			Errorf("zeta of 1")
+		case i == 0:
+			return BigRat{big.NewRat(-1, 2)}
+		case i < 0:
+			// ζ(-n) = -B(n+1)/(n+1).
+			b := new(big.Rat).Neg(bernoulli(int(1 - i)))
+			return BigRat{b.Quo(b, big.NewRat(int64(1-i), 1))}.shrink()
+		case i%2 == 0 && i <= 1000:
+			// ζ(2n) = (-1)**(n+1) B(2n) (2π)**(2n) / 2(2n)!.
+			b := new(big.Rat).Abs(bernoulli(int(i)))
+			b.Quo(b, new(big.Rat).SetInt(new(big.Int).Mul(big.NewInt(2), factorial(int64(i)))))
+			twoPi := BigFloat{newFloat(c).Mul(floatPi, floatTwo)}
+			return c.EvalBinary(BigRat{b}, "*", c.EvalBinary(twoPi, "**", i))
+		}
+	}
+	s := floatValue(c, v)
+	re, im := parts(c, s)
+	if re.Cmp(floatHalf) < 0 {
+		// The functional equation:
+		// ζ(s) = 2**s π**(s-1) sin(πs/2) Γ(1-s) ζ(1-s).
+		pi := BigFloat{floatPi}
+		oneMinusS := c.EvalBinary(one, "-", s)
+		z := c.EvalBinary(Int(2), "**", s)
+		z = c.EvalBinary(z, "*", c.EvalBinary(pi, "**", c.EvalUnary("-", oneMinusS)))
+		z = c.EvalBinary(z, "*", c.EvalUnary("sin", c.EvalBinary(c.EvalBinary(pi, "*", s), "/", Int(2))))
+		z = c.EvalBinary(z, "*", gamma(c, oneMinusS))
+		return c.EvalBinary(z, "*", zeta(c, oneMinusS))
+	}
+	if re.Cmp(floatOne) == 0 && im.Sign() == 0 {
+		Errorf("zeta of 1")
+	}
+	return borwein(c, s)
+}
+
+// borwein computes ζ(s) for Re s >= 1/2 using the algorithm of
+// P. Borwein, "An Efficient Algorithm for the Riemann Zeta Function":
+//
+//	ζ(s) = -1/(d(n)(1-2**(1-s))) Σ_{k<n} (-1)**k (d(k)-d(n))/(k+1)**s
+//
+// where d(k) = n Σ_{i≤k} (n+i-1)! 4**i/((n-i)!(2i)!). The error is about
+// (3+√8)**-n, growing with the imaginary part of s.
+func borwein(c Context, s Value) Value {
+	_, im := parts(c, s)
+	t, _ := new(big.Float).Abs(im).Float64()
+	bits := float64(c.Config().FloatPrec()) + 2 + t*math.Pi/2*math.Log2E + math.Log2(1+2*t)
+	n := int64(bits/math.Log2(3+math.Sqrt(8))) + 1
+	// The d(k) are integers. Each term of the sum is the previous one
+	// times 2(n+i)(n-i)/((2i+1)(i+1)).
+	d := make([]*big.Int, n+1)
+	term := big.NewInt(1)
+	sum := big.NewInt(1)
+	d[0] = new(big.Int).Set(sum)
+	tmp := new(big.Int)
+	for i := int64(0); i < n; i++ {
+		term.Mul(term, tmp.SetInt64(2*(n+i)*(n-i)))
+		term.Quo(term, tmp.SetInt64((2*i+1)*(i+1)))
+		sum.Add(sum, term)
+		d[i+1] = new(big.Int).Set(sum)
+	}
+	negS := c.EvalUnary("-", s)
+	var z Value = zero
+	for k := int64(0); k < n; k++ {
+		coef := BigInt{new(big.Int).Sub(d[k], d[n])}.shrink()
+		if k%2 == 1 {
+			coef = c.EvalUnary("-", coef)
+		}
+		base := BigFloat{newFloat(c).SetInt64(k + 1)}
+		z = c.EvalBinary(z, "+", c.EvalBinary(coef, "*", c.EvalBinary(base, "**", negS)))
+	}
+	den := c.EvalBinary(one, "-", c.EvalBinary(Int(2), "**", c.EvalBinary(one, "-", s)))
+	den = c.EvalBinary(den, "*", BigInt{d[n]})
+	return c.EvalUnary("-", c.EvalBinary(z, "/", den))
+}
+
+// besselOrder returns the order of a Bessel function, which must be real,
+// and whether it is an integer.
+func besselOrder(c Context, name string, v Value) (Value, bool) {
+	if z, ok := v.(Complex); ok {
+		// The order may have been promoted to match a complex argument.
+		v = z.shrink()
+	}
+	if !isReal(v) {
+		Errorf("%s: complex order %s", name, v.Sprint(c.Config()))
+	}
+	if i, ok := v.(Int); ok {
+		return i, true
+	}
+	f := floatSelf(c, v).(BigFloat)
+	if i, acc := f.Int64(); acc == big.Exact {
+		return Int(i), true
+	}
+	return f, false
+}
+
+// besselJ returns the Bessel function of the first kind of order u, J_u(v).
+func besselJ(c Context, u, v Value) Value {
+	nu, isInt := besselOrder(c, "besselj", u)
+	if isInt && nu.(Int) < 0 {
+		// J_-n(x) = (-1)**n J_n(x).
+		j := besselJ(c, -nu.(Int), v)
+		if nu.(Int)%2 != 0 {
+			j = c.EvalUnary("-", j)
+		}
+		return j
+	}
+	j, _ := besselSeries(c, nu, v, false)
+	return j
+}
+
+// besselSeries sums the series
+//
+//	J_ν(x) = Σ (-1)**k (x/2)**(2k+ν) / (k! Γ(k+ν+1))
+//
+// If harmonic is set, it also returns Σ (H(k)+H(k+ν)) times the terms,
+// where H(k) is the k'th harmonic number, as needed by besselY.
+func besselSeries(c Context, nu, x Value, harmonic bool) (sum, hsum Value) {
+	x = floatValue(c, x)
+	halfX := c.EvalBinary(x, "/", Int(2))
+	negQuarterX2 := c.EvalUnary("-", c.EvalBinary(halfX, "*", halfX))
+	term := c.EvalBinary(c.EvalBinary(halfX, "**", nu), "/", gamma(c, c.EvalBinary(nu, "+", one)))
+	sum = term
+	hsum = zero
+	var hk, hnk Value = zero, zero // H(k), H(k+ν)
+	if harmonic {
+		// ν is a non-negative integer.
+		hnk = BigRat{harmonicNumber(int64(nu.(Int)))}.shrink()
+		hsum = c.EvalBinary(hnk, "*", term)
+	}
+	loop := newValueLoop(c, "bessel", x, 4)
+	hloop := newValueLoop(c, "bessel", x, 4)
+	for k := Int(1); ; k++ {
+		term = c.EvalBinary(term, "*", negQuarterX2)
+		term = c.EvalBinary(term, "/", c.EvalBinary(k, "*", c.EvalBinary(k, "+", nu)))
+		sum = c.EvalBinary(sum, "+", term)
+		if harmonic {
+			hk = c.EvalBinary(hk, "+", c.EvalBinary(one, "/", k))
+			hnk = c.EvalBinary(hnk, "+", c.EvalBinary(one, "/", c.EvalBinary(k, "+", nu)))
+			hsum = c.EvalBinary(hsum, "+", c.EvalBinary(c.EvalBinary(hk, "+", hnk), "*", term))
+		}
+		// Stop when both sums have converged.
+		if loop.done(c, sum) && (!harmonic || hloop.done(c, hsum)) {
+			break
+		}
+	}
+	// With integer x, the sums may be exact rationals.
+	return floatValue(c, sum), floatValue(c, hsum)
+}
+
+// harmonicNumber returns H(n) = 1 + 1/2 + ... + 1/n.
+func harmonicNumber(n int64) *big.Rat {
+	h := new(big.Rat)
+	for k := int64(1); k <= n; k++ {
+		h.Add(h, big.NewRat(1, k))
+	}
+	return h
+}
+
+// besselY returns the Bessel function of the second kind of order u, Y_u(v).
+func besselY(c Context, u, v Value) Value {
+	nu, isInt := besselOrder(c, "bessely", u)
+	if isReal(v) && floatSelf(c, v).(BigFloat).Sign() == 0 {
+		Errorf("bessely of zero")
+	}
+	pi := BigFloat{floatPi}
+	if !isInt {
+		// Y_ν(x) = (J_ν(x) cos νπ - J_-ν(x)) / sin νπ.
+		nuPi := c.EvalBinary(nu, "*", pi)
+		y := c.EvalBinary(besselJ(c, nu, v), "*", c.EvalUnary("cos", nuPi))
+		y = c.EvalBinary(y, "-", besselJ(c, c.EvalUnary("-", nu), v))
+		return c.EvalBinary(y, "/", c.EvalUnary("sin", nuPi))
+	}
+	n := nu.(Int)
+	if n < 0 {
+		// Y_-n(x) = (-1)**n Y_n(x).
+		y := besselY(c, -n, v)
+		if n%2 != 0 {
+			y = c.EvalUnary("-", y)
+		}
+		return y
+	}
+	// For integer n,
+	//	πY_n(x) = 2 J_n(x) log(x/2) - Σ_{k<n} (n-k-1)!/k! (x/2)**(2k-n)
+	//		- Σ_k (ψ(k+1)+ψ(n+k+1)) (-x²/4)**k (x/2)**n / (k!(n+k)!)
+	// where ψ(k+1) = H(k) - γ.
+	x := floatValue(c, v)
+	halfX := c.EvalBinary(x, "/", Int(2))
+	j, hsum := besselSeries(c, n, x, true)
+	euler := BigFloat{constEuler.value(c.Config().FloatPrec())}
+	y := c.EvalBinary(c.EvalBinary(Int(2), "*", j), "*", c.EvalBinary(c.EvalUnary("log", halfX), "+", euler))
+	y = c.EvalBinary(y, "-", hsum)
+	for k := Int(0); k < n; k++ {
+		coef := BigRat{new(big.Rat).SetFrac(factorial(int64(n-k-1)), factorial(int64(k)))}.shrink()
+		y = c.EvalBinary(y, "-", c.EvalBinary(coef, "*", c.EvalBinary(halfX, "**", 2*k-n)))
+	}
+	return c.EvalBinary(y, "/", pi)
+}
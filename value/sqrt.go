@@ -9,6 +9,16 @@ import "math/big"
 // domain: [0, ∞) - complex solution outside of domain
 // range: [0, ∞)
 func sqrt(c Context, v Value) Value {
+	// Perfect squares have exact roots.
+	if x, ok := exactRat(v); ok {
+		neg := x.Sign() < 0
+		if r, ok := ratRoot(new(big.Rat).Abs(x), 2); ok {
+			if neg {
+				return newComplexImag(BigRat{r}.shrink())
+			}
+			return BigRat{r}.shrink()
+		}
+	}
 	f := floatSelf(c, v).(BigFloat).Float
 	// For negative x: sqrt(-x) = sqrt(x)i
 	if f.Sign() == -1 {
@@ -58,3 +68,62 @@ func floatSqrt(c Context, x *big.Float) *big.Float {
 	}
 	return z
 }
+
+// exactRat returns the value of v as a rational if it is an integer or rational.
+func exactRat(v Value) (*big.Rat, bool) {
+	switch v := v.(type) {
+	case Int:
+		return big.NewRat(int64(v), 1), true
+	case BigInt:
+		return new(big.Rat).SetInt(v.Int), true
+	case BigRat:
+		return v.Rat, true
+	}
+	return nil, false
+}
+
+// bigIntRoot returns the integer n'th root of x >= 0,
+// the largest z such that z**n <= x.
+func bigIntRoot(x *big.Int, n uint) *big.Int {
+	switch {
+	case x.Sign() == 0 || n == 1:
+		return new(big.Int).Set(x)
+	case n == 2:
+		return new(big.Int).Sqrt(x)
+	case uint(x.BitLen()) <= n:
+		// 1 <= x < 2**n.
+		return big.NewInt(1)
+	}
+	// Newton's method, starting above the root, which makes the
+	// iterates decrease monotonically to the floor of the root:
+	//	z = ((n-1)z + x/z**(n-1)) / n
+	z := new(big.Int).Lsh(bigOne.Int, (uint(x.BitLen())+n-1)/n)
+	bn := new(big.Int).SetUint64(uint64(n))
+	bn1 := new(big.Int).SetUint64(uint64(n - 1))
+	y := new(big.Int)
+	t := new(big.Int)
+	for {
+		t.Exp(z, bn1, nil)
+		t.Quo(x, t)
+		y.Mul(z, bn1)
+		y.Add(y, t)
+		y.Quo(y, bn)
+		if y.Cmp(z) >= 0 {
+			return z
+		}
+		z, y = y, z
+	}
+}
+
+// ratRoot returns the n'th root of x >= 0, and whether it is exact.
+func ratRoot(x *big.Rat, n uint) (*big.Rat, bool) {
+	num := bigIntRoot(x.Num(), n)
+	if new(big.Int).Exp(num, big.NewInt(int64(n)), nil).Cmp(x.Num()) != 0 {
+		return nil, false
+	}
+	den := bigIntRoot(x.Denom(), n)
+	if new(big.Int).Exp(den, big.NewInt(int64(n)), nil).Cmp(x.Denom()) != 0 {
+		return nil, false
+	}
+	return new(big.Rat).SetFrac(num, den), true
+}
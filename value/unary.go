@@ -5,7 +5,9 @@
 package value
 
 import (
+	"math"
 	"math/big"
+	"math/cmplx"
 	"unicode/utf8"
 )
 
@@ -92,6 +94,8 @@ func floatSelf(c Context, v Value) Value {
 		return v.toType("float", conf, bigFloatType)
 	case BigRat:
 		return v.toType("float", conf, bigFloatType)
+	case Float:
+		return v.toType("float", conf, bigFloatType)
 	case BigFloat:
 		return v
 	case Complex:
@@ -158,6 +162,7 @@ func init() {
 				bigIntType:   self,
 				bigRatType:   self,
 				bigFloatType: self,
+				intervalType: self,
 				complexType:  self,
 				vectorType:   self,
 				matrixType:   self,
@@ -177,9 +182,18 @@ func init() {
 				bigRatType: func(c Context, v Value) Value {
 					return unaryBigRatOp((*big.Rat).Neg, v)
 				},
+				floatType: func(c Context, v Value) Value {
+					return -v.(Float)
+				},
 				bigFloatType: func(c Context, v Value) Value {
 					return unaryBigFloatOp(c, bigFloatWrap((*big.Float).Neg), v)
 				},
+				intervalType: func(c Context, v Value) Value {
+					return intervalNeg(c, v.(Interval))
+				},
+				complex128Type: func(c Context, v Value) Value {
+					return -v.(Complex128)
+				},
 				complexType: func(c Context, v Value) Value {
 					return unaryComplexOp(c, (Complex).Neg, v)
 				},
@@ -212,6 +226,10 @@ func init() {
 						Rat: big.NewRat(0, 1).SetFrac(r.Denom(), r.Num()),
 					}.shrink()
 				},
+				floatType: func(c Context, v Value) Value {
+					// Zero division cannot happen for unary.
+					return machineFloat(1 / float64(v.(Float)))
+				},
 				bigFloatType: func(c Context, v Value) Value {
 					// Zero division cannot happen for unary.
 					f := v.(BigFloat)
@@ -220,6 +238,12 @@ func init() {
 						Float: one.Quo(one, f.Float),
 					}.shrink()
 				},
+				intervalType: func(c Context, v Value) Value {
+					return intervalQuo(c, intervalOf(c, one), v.(Interval))
+				},
+				complex128Type: func(c Context, v Value) Value {
+					return unaryComplex128Op(c, "/", func(z complex128) complex128 { return 1 / z }, v)
+				},
 				complexType: func(c Context, v Value) Value {
 					// Zero division cannot happen, the zero complex would have been shrunk.
 					z := v.(Complex)
@@ -248,6 +272,16 @@ func init() {
 				bigRatType: func(c Context, v Value) Value {
 					return Int(v.(BigRat).Sign())
 				},
+				floatType: func(c Context, v Value) Value {
+					f := v.(Float)
+					if f > 0 {
+						return one
+					}
+					if f < 0 {
+						return minusOne
+					}
+					return zero
+				},
 				bigFloatType: func(c Context, v Value) Value {
 					return Int(v.(BigFloat).Sign())
 				},
@@ -335,9 +369,18 @@ func init() {
 				bigRatType: func(c Context, v Value) Value {
 					return unaryBigRatOp((*big.Rat).Abs, v)
 				},
+				floatType: func(c Context, v Value) Value {
+					return Float(math.Abs(float64(v.(Float))))
+				},
 				bigFloatType: func(c Context, v Value) Value {
 					return unaryBigFloatOp(c, bigFloatWrap((*big.Float).Abs), v)
 				},
+				intervalType: func(c Context, v Value) Value {
+					return intervalAbs(c, v.(Interval))
+				},
+				complex128Type: func(c Context, v Value) Value {
+					return machineFloat(cmplx.Abs(complex128(v.(Complex128))))
+				},
 				complexType: func(c Context, v Value) Value {
 					return v.(Complex).Abs(c)
 				},
@@ -370,6 +413,7 @@ func init() {
 					}
 					return z.shrink()
 				},
+				floatType: func(c Context, v Value) Value { return unaryFloatOp(math.Floor, v) },
 				bigFloatType: func(c Context, v Value) Value {
 					f := v.(BigFloat)
 					if f.Float.IsInf() {
@@ -417,6 +461,7 @@ func init() {
 					}
 					return z.shrink()
 				},
+				floatType: func(c Context, v Value) Value { return unaryFloatOp(math.Ceil, v) },
 				bigFloatType: func(c Context, v Value) Value {
 					f := v.(BigFloat)
 					if f.Float.IsInf() {
@@ -467,6 +512,9 @@ func init() {
 				bigIntType:   self,
 				bigRatType:   self,
 				bigFloatType: self,
+				complex128Type: func(c Context, v Value) Value {
+					return machineFloat(real(v.(Complex128)))
+				},
 				complexType: func(c Context, v Value) Value {
 					return v.(Complex).Real()
 				},
@@ -489,6 +537,9 @@ func init() {
 				bigFloatType: func(c Context, v Value) Value {
 					return zero
 				},
+				complex128Type: func(c Context, v Value) Value {
+					return machineFloat(imag(v.(Complex128)))
+				},
 				complexType: func(c Context, v Value) Value {
 					return v.(Complex).Imag()
 				},
@@ -558,6 +609,9 @@ func init() {
 				complexType: func(c Context, v Value) Value {
 					return Int(0)
 				},
+				intervalType: func(c Context, v Value) Value {
+					return Int(0)
+				},
 				vectorType: func(c Context, v Value) Value {
 					return Int(len(v.(Vector)))
 				},
@@ -576,6 +630,7 @@ func init() {
 				bigRatType:   vectorSelf,
 				bigFloatType: vectorSelf,
 				complexType:  vectorSelf,
+				intervalType: vectorSelf,
 				vectorType:   self,
 				matrixType: func(c Context, v Value) Value {
 					return v.(*Matrix).data.Copy()
@@ -715,11 +770,19 @@ func init() {
 			name:        "log",
 			elementwise: true,
 			fn: [numType]unaryFn{
-				intType:      func(c Context, v Value) Value { return logn(c, v) },
-				bigIntType:   func(c Context, v Value) Value { return logn(c, v) },
-				bigRatType:   func(c Context, v Value) Value { return logn(c, v) },
-				bigFloatType: func(c Context, v Value) Value { return logn(c, v) },
-				complexType:  func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Log, v) },
+				intType:    func(c Context, v Value) Value { return logn(c, v) },
+				bigIntType: func(c Context, v Value) Value { return logn(c, v) },
+				bigRatType: func(c Context, v Value) Value { return logn(c, v) },
+				floatType: func(c Context, v Value) Value {
+					if v.(Float) <= 0 {
+						return floatFallback(c, UnaryOps["log"].(*unaryOp), v)
+					}
+					return unaryFloatOp(math.Log, v)
+				},
+				bigFloatType:   func(c Context, v Value) Value { return logn(c, v) },
+				intervalType:   func(c Context, v Value) Value { return intervalLog(c, v.(Interval)) },
+				complex128Type: func(c Context, v Value) Value { return unaryComplex128Op(c, "log", cmplx.Log, v) },
+				complexType:    func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Log, v) },
 			},
 		},
 
@@ -727,11 +790,14 @@ func init() {
 			name:        "sin",
 			elementwise: true,
 			fn: [numType]unaryFn{
-				intType:      func(c Context, v Value) Value { return sin(c, v) },
-				bigIntType:   func(c Context, v Value) Value { return sin(c, v) },
-				bigRatType:   func(c Context, v Value) Value { return sin(c, v) },
-				bigFloatType: func(c Context, v Value) Value { return sin(c, v) },
-				complexType:  func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Sin, v) },
+				intType:        func(c Context, v Value) Value { return sin(c, v) },
+				bigIntType:     func(c Context, v Value) Value { return sin(c, v) },
+				bigRatType:     func(c Context, v Value) Value { return sin(c, v) },
+				floatType:      func(c Context, v Value) Value { return unaryFloatOp(math.Sin, v) },
+				bigFloatType:   func(c Context, v Value) Value { return sin(c, v) },
+				intervalType:   func(c Context, v Value) Value { return intervalSinCos(c, v.(Interval), false) },
+				complex128Type: func(c Context, v Value) Value { return unaryComplex128Op(c, "sin", cmplx.Sin, v) },
+				complexType:    func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Sin, v) },
 			},
 		},
 
@@ -739,11 +805,14 @@ func init() {
 			name:        "cos",
 			elementwise: true,
 			fn: [numType]unaryFn{
-				intType:      func(c Context, v Value) Value { return cos(c, v) },
-				bigIntType:   func(c Context, v Value) Value { return cos(c, v) },
-				bigRatType:   func(c Context, v Value) Value { return cos(c, v) },
-				bigFloatType: func(c Context, v Value) Value { return cos(c, v) },
-				complexType:  func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Cos, v) },
+				intType:        func(c Context, v Value) Value { return cos(c, v) },
+				bigIntType:     func(c Context, v Value) Value { return cos(c, v) },
+				bigRatType:     func(c Context, v Value) Value { return cos(c, v) },
+				floatType:      func(c Context, v Value) Value { return unaryFloatOp(math.Cos, v) },
+				bigFloatType:   func(c Context, v Value) Value { return cos(c, v) },
+				intervalType:   func(c Context, v Value) Value { return intervalSinCos(c, v.(Interval), true) },
+				complex128Type: func(c Context, v Value) Value { return unaryComplex128Op(c, "cos", cmplx.Cos, v) },
+				complexType:    func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Cos, v) },
 			},
 		},
 
@@ -751,11 +820,14 @@ func init() {
 			name:        "tan",
 			elementwise: true,
 			fn: [numType]unaryFn{
-				intType:      func(c Context, v Value) Value { return tan(c, v) },
-				bigIntType:   func(c Context, v Value) Value { return tan(c, v) },
-				bigRatType:   func(c Context, v Value) Value { return tan(c, v) },
-				bigFloatType: func(c Context, v Value) Value { return tan(c, v) },
-				complexType:  func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Tan, v) },
+				intType:        func(c Context, v Value) Value { return tan(c, v) },
+				bigIntType:     func(c Context, v Value) Value { return tan(c, v) },
+				bigRatType:     func(c Context, v Value) Value { return tan(c, v) },
+				floatType:      func(c Context, v Value) Value { return unaryFloatOp(math.Tan, v) },
+				bigFloatType:   func(c Context, v Value) Value { return tan(c, v) },
+				intervalType:   func(c Context, v Value) Value { return intervalTan(c, v.(Interval)) },
+				complex128Type: func(c Context, v Value) Value { return unaryComplex128Op(c, "tan", cmplx.Tan, v) },
+				complexType:    func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Tan, v) },
 			},
 		},
 
@@ -763,11 +835,12 @@ func init() {
 			name:        "asin",
 			elementwise: true,
 			fn: [numType]unaryFn{
-				intType:      func(c Context, v Value) Value { return asin(c, v) },
-				bigIntType:   func(c Context, v Value) Value { return asin(c, v) },
-				bigRatType:   func(c Context, v Value) Value { return asin(c, v) },
-				bigFloatType: func(c Context, v Value) Value { return asin(c, v) },
-				complexType:  func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Asin, v) },
+				intType:        func(c Context, v Value) Value { return asin(c, v) },
+				bigIntType:     func(c Context, v Value) Value { return asin(c, v) },
+				bigRatType:     func(c Context, v Value) Value { return asin(c, v) },
+				bigFloatType:   func(c Context, v Value) Value { return asin(c, v) },
+				complex128Type: func(c Context, v Value) Value { return unaryComplex128Op(c, "asin", cmplx.Asin, v) },
+				complexType:    func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Asin, v) },
 			},
 		},
 
@@ -775,11 +848,12 @@ func init() {
 			name:        "acos",
 			elementwise: true,
 			fn: [numType]unaryFn{
-				intType:      func(c Context, v Value) Value { return acos(c, v) },
-				bigIntType:   func(c Context, v Value) Value { return acos(c, v) },
-				bigRatType:   func(c Context, v Value) Value { return acos(c, v) },
-				bigFloatType: func(c Context, v Value) Value { return acos(c, v) },
-				complexType:  func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Acos, v) },
+				intType:        func(c Context, v Value) Value { return acos(c, v) },
+				bigIntType:     func(c Context, v Value) Value { return acos(c, v) },
+				bigRatType:     func(c Context, v Value) Value { return acos(c, v) },
+				bigFloatType:   func(c Context, v Value) Value { return acos(c, v) },
+				complex128Type: func(c Context, v Value) Value { return unaryComplex128Op(c, "acos", cmplx.Acos, v) },
+				complexType:    func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Acos, v) },
 			},
 		},
 
@@ -787,11 +861,14 @@ func init() {
 			name:        "atan",
 			elementwise: true,
 			fn: [numType]unaryFn{
-				intType:      func(c Context, v Value) Value { return atan(c, v) },
-				bigIntType:   func(c Context, v Value) Value { return atan(c, v) },
-				bigRatType:   func(c Context, v Value) Value { return atan(c, v) },
-				bigFloatType: func(c Context, v Value) Value { return atan(c, v) },
-				complexType:  func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Atan, v) },
+				intType:        func(c Context, v Value) Value { return atan(c, v) },
+				bigIntType:     func(c Context, v Value) Value { return atan(c, v) },
+				bigRatType:     func(c Context, v Value) Value { return atan(c, v) },
+				floatType:      func(c Context, v Value) Value { return unaryFloatOp(math.Atan, v) },
+				bigFloatType:   func(c Context, v Value) Value { return atan(c, v) },
+				intervalType:   func(c Context, v Value) Value { return intervalAtan(c, v.(Interval)) },
+				complex128Type: func(c Context, v Value) Value { return unaryComplex128Op(c, "atan", cmplx.Atan, v) },
+				complexType:    func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Atan, v) },
 			},
 		},
 
@@ -799,11 +876,14 @@ func init() {
 			name:        "**",
 			elementwise: true,
 			fn: [numType]unaryFn{
-				intType:      func(c Context, v Value) Value { return exp(c, v) },
-				bigIntType:   func(c Context, v Value) Value { return exp(c, v) },
-				bigRatType:   func(c Context, v Value) Value { return exp(c, v) },
-				bigFloatType: func(c Context, v Value) Value { return exp(c, v) },
-				complexType:  func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Exp, v) },
+				intType:        func(c Context, v Value) Value { return exp(c, v) },
+				bigIntType:     func(c Context, v Value) Value { return exp(c, v) },
+				bigRatType:     func(c Context, v Value) Value { return exp(c, v) },
+				floatType:      func(c Context, v Value) Value { return unaryFloatOp(math.Exp, v) },
+				bigFloatType:   func(c Context, v Value) Value { return exp(c, v) },
+				intervalType:   func(c Context, v Value) Value { return intervalExp(c, v.(Interval)) },
+				complex128Type: func(c Context, v Value) Value { return unaryComplex128Op(c, "**", cmplx.Exp, v) },
+				complexType:    func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Exp, v) },
 			},
 		},
 
@@ -811,11 +891,14 @@ func init() {
 			name:        "sinh",
 			elementwise: true,
 			fn: [numType]unaryFn{
-				intType:      func(c Context, v Value) Value { return sinh(c, v) },
-				bigIntType:   func(c Context, v Value) Value { return sinh(c, v) },
-				bigRatType:   func(c Context, v Value) Value { return sinh(c, v) },
-				bigFloatType: func(c Context, v Value) Value { return sinh(c, v) },
-				complexType:  func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Sinh, v) },
+				intType:        func(c Context, v Value) Value { return sinh(c, v) },
+				bigIntType:     func(c Context, v Value) Value { return sinh(c, v) },
+				bigRatType:     func(c Context, v Value) Value { return sinh(c, v) },
+				floatType:      func(c Context, v Value) Value { return unaryFloatOp(math.Sinh, v) },
+				bigFloatType:   func(c Context, v Value) Value { return sinh(c, v) },
+				intervalType:   func(c Context, v Value) Value { return intervalSinh(c, v.(Interval)) },
+				complex128Type: func(c Context, v Value) Value { return unaryComplex128Op(c, "sinh", cmplx.Sinh, v) },
+				complexType:    func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Sinh, v) },
 			},
 		},
 
@@ -823,11 +906,14 @@ func init() {
 			name:        "cosh",
 			elementwise: true,
 			fn: [numType]unaryFn{
-				intType:      func(c Context, v Value) Value { return cosh(c, v) },
-				bigIntType:   func(c Context, v Value) Value { return cosh(c, v) },
-				bigRatType:   func(c Context, v Value) Value { return cosh(c, v) },
-				bigFloatType: func(c Context, v Value) Value { return cosh(c, v) },
-				complexType:  func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Cosh, v) },
+				intType:        func(c Context, v Value) Value { return cosh(c, v) },
+				bigIntType:     func(c Context, v Value) Value { return cosh(c, v) },
+				bigRatType:     func(c Context, v Value) Value { return cosh(c, v) },
+				floatType:      func(c Context, v Value) Value { return unaryFloatOp(math.Cosh, v) },
+				bigFloatType:   func(c Context, v Value) Value { return cosh(c, v) },
+				intervalType:   func(c Context, v Value) Value { return intervalCosh(c, v.(Interval)) },
+				complex128Type: func(c Context, v Value) Value { return unaryComplex128Op(c, "cosh", cmplx.Cosh, v) },
+				complexType:    func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Cosh, v) },
 			},
 		},
 
@@ -835,11 +921,14 @@ func init() {
 			name:        "tanh",
 			elementwise: true,
 			fn: [numType]unaryFn{
-				intType:      func(c Context, v Value) Value { return tanh(c, v) },
-				bigIntType:   func(c Context, v Value) Value { return tanh(c, v) },
-				bigRatType:   func(c Context, v Value) Value { return tanh(c, v) },
-				bigFloatType: func(c Context, v Value) Value { return tanh(c, v) },
-				complexType:  func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Tanh, v) },
+				intType:        func(c Context, v Value) Value { return tanh(c, v) },
+				bigIntType:     func(c Context, v Value) Value { return tanh(c, v) },
+				bigRatType:     func(c Context, v Value) Value { return tanh(c, v) },
+				floatType:      func(c Context, v Value) Value { return unaryFloatOp(math.Tanh, v) },
+				bigFloatType:   func(c Context, v Value) Value { return tanh(c, v) },
+				intervalType:   func(c Context, v Value) Value { return intervalTanh(c, v.(Interval)) },
+				complex128Type: func(c Context, v Value) Value { return unaryComplex128Op(c, "tanh", cmplx.Tanh, v) },
+				complexType:    func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Tanh, v) },
 			},
 		},
 
@@ -847,11 +936,12 @@ func init() {
 			name:        "asinh",
 			elementwise: true,
 			fn: [numType]unaryFn{
-				intType:      func(c Context, v Value) Value { return asinh(c, v) },
-				bigIntType:   func(c Context, v Value) Value { return asinh(c, v) },
-				bigRatType:   func(c Context, v Value) Value { return asinh(c, v) },
-				bigFloatType: func(c Context, v Value) Value { return asinh(c, v) },
-				complexType:  func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Asinh, v) },
+				intType:        func(c Context, v Value) Value { return asinh(c, v) },
+				bigIntType:     func(c Context, v Value) Value { return asinh(c, v) },
+				bigRatType:     func(c Context, v Value) Value { return asinh(c, v) },
+				bigFloatType:   func(c Context, v Value) Value { return asinh(c, v) },
+				complex128Type: func(c Context, v Value) Value { return unaryComplex128Op(c, "asinh", cmplx.Asinh, v) },
+				complexType:    func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Asinh, v) },
 			},
 		},
 
@@ -859,11 +949,12 @@ func init() {
 			name:        "acosh",
 			elementwise: true,
 			fn: [numType]unaryFn{
-				intType:      func(c Context, v Value) Value { return acosh(c, v) },
-				bigIntType:   func(c Context, v Value) Value { return acosh(c, v) },
-				bigRatType:   func(c Context, v Value) Value { return acosh(c, v) },
-				bigFloatType: func(c Context, v Value) Value { return acosh(c, v) },
-				complexType:  func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Acosh, v) },
+				intType:        func(c Context, v Value) Value { return acosh(c, v) },
+				bigIntType:     func(c Context, v Value) Value { return acosh(c, v) },
+				bigRatType:     func(c Context, v Value) Value { return acosh(c, v) },
+				bigFloatType:   func(c Context, v Value) Value { return acosh(c, v) },
+				complex128Type: func(c Context, v Value) Value { return unaryComplex128Op(c, "acosh", cmplx.Acosh, v) },
+				complexType:    func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Acosh, v) },
 			},
 		},
 
@@ -871,11 +962,12 @@ func init() {
 			name:        "atanh",
 			elementwise: true,
 			fn: [numType]unaryFn{
-				intType:      func(c Context, v Value) Value { return atanh(c, v) },
-				bigIntType:   func(c Context, v Value) Value { return atanh(c, v) },
-				bigRatType:   func(c Context, v Value) Value { return atanh(c, v) },
-				bigFloatType: func(c Context, v Value) Value { return atanh(c, v) },
-				complexType:  func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Atanh, v) },
+				intType:        func(c Context, v Value) Value { return atanh(c, v) },
+				bigIntType:     func(c Context, v Value) Value { return atanh(c, v) },
+				bigRatType:     func(c Context, v Value) Value { return atanh(c, v) },
+				bigFloatType:   func(c Context, v Value) Value { return atanh(c, v) },
+				complex128Type: func(c Context, v Value) Value { return unaryComplex128Op(c, "atanh", cmplx.Atanh, v) },
+				complexType:    func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Atanh, v) },
 			},
 		},
 
@@ -883,11 +975,144 @@ func init() {
 			name:        "sqrt",
 			elementwise: true,
 			fn: [numType]unaryFn{
-				intType:      func(c Context, v Value) Value { return sqrt(c, v) },
-				bigIntType:   func(c Context, v Value) Value { return sqrt(c, v) },
-				bigRatType:   func(c Context, v Value) Value { return sqrt(c, v) },
-				bigFloatType: func(c Context, v Value) Value { return sqrt(c, v) },
-				complexType:  func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Sqrt, v) },
+				intType:    func(c Context, v Value) Value { return sqrt(c, v) },
+				bigIntType: func(c Context, v Value) Value { return sqrt(c, v) },
+				bigRatType: func(c Context, v Value) Value { return sqrt(c, v) },
+				floatType: func(c Context, v Value) Value {
+					if v.(Float) < 0 {
+						return floatFallback(c, UnaryOps["sqrt"].(*unaryOp), v)
+					}
+					return unaryFloatOp(math.Sqrt, v)
+				},
+				bigFloatType:   func(c Context, v Value) Value { return sqrt(c, v) },
+				intervalType:   func(c Context, v Value) Value { return intervalSqrt(c, v.(Interval)) },
+				complex128Type: func(c Context, v Value) Value { return unaryComplex128Op(c, "sqrt", cmplx.Sqrt, v) },
+				complexType:    func(c Context, v Value) Value { return unaryComplexOp(c, (Complex).Sqrt, v) },
+			},
+		},
+
+		{
+			name:        "gamma",
+			elementwise: true,
+			fn: [numType]unaryFn{
+				intType:      gamma,
+				bigIntType:   gamma,
+				bigRatType:   gamma,
+				bigFloatType: gamma,
+				complexType:  gamma,
+			},
+		},
+
+		{
+			name:        "lgamma",
+			elementwise: true,
+			fn: [numType]unaryFn{
+				intType:      lgamma,
+				bigIntType:   lgamma,
+				bigRatType:   lgamma,
+				bigFloatType: lgamma,
+				complexType:  lgamma,
+			},
+		},
+
+		{
+			name:        "erf",
+			elementwise: true,
+			fn: [numType]unaryFn{
+				intType:      erf,
+				bigIntType:   erf,
+				bigRatType:   erf,
+				bigFloatType: erf,
+				complexType:  erf,
+			},
+		},
+
+		{
+			name:        "erfc",
+			elementwise: true,
+			fn: [numType]unaryFn{
+				intType:      erfc,
+				bigIntType:   erfc,
+				bigRatType:   erfc,
+				bigFloatType: erfc,
+				complexType:  erfc,
+			},
+		},
+
+		{
+			name:        "zeta",
+			elementwise: true,
+			fn: [numType]unaryFn{
+				intType:      zeta,
+				bigIntType:   zeta,
+				bigRatType:   zeta,
+				bigFloatType: zeta,
+				complexType:  zeta,
+			},
+		},
+
+		{
+			name:        "isprime",
+			elementwise: true,
+			fn: [numType]unaryFn{
+				intType:    isprime,
+				bigIntType: isprime,
+			},
+		},
+
+		{
+			name:        "nextprime",
+			elementwise: true,
+			fn: [numType]unaryFn{
+				intType:    nextprime,
+				bigIntType: nextprime,
+			},
+		},
+
+		{
+			name: "factor",
+			fn: [numType]unaryFn{
+				intType:    factor,
+				bigIntType: factor,
+				vectorType: factorVector,
+			},
+		},
+
+		{
+			name:        "totient",
+			elementwise: true,
+			fn: [numType]unaryFn{
+				intType:    totient,
+				bigIntType: totient,
+			},
+		},
+
+		{
+			name:        "isqrt",
+			elementwise: true,
+			fn: [numType]unaryFn{
+				intType:    isqrt,
+				bigIntType: isqrt,
+			},
+		},
+
+		{
+			name: "cf",
+			fn: [numType]unaryFn{
+				intType:      cf,
+				bigIntType:   cf,
+				bigRatType:   cf,
+				floatType:    cf,
+				bigFloatType: cf,
+			},
+		},
+
+		{
+			name: "uncf",
+			fn: [numType]unaryFn{
+				intType:    self,
+				bigIntType: self,
+				vectorType: uncf,
 			},
 		},
 
@@ -915,6 +1140,7 @@ func init() {
 				bigRatType:   func(c Context, v Value) Value { return text(c, v) },
 				bigFloatType: func(c Context, v Value) Value { return text(c, v) },
 				complexType:  func(c Context, v Value) Value { return text(c, v) },
+				intervalType: func(c Context, v Value) Value { return text(c, v) },
 				vectorType:   func(c Context, v Value) Value { return text(c, v) },
 				matrixType:   func(c Context, v Value) Value { return text(c, v) },
 			},
@@ -937,6 +1163,67 @@ func init() {
 			},
 		},
 
+		{
+			name: "json",
+			fn: [numType]unaryFn{
+				intType:      jsonText,
+				charType:     jsonText,
+				bigIntType:   jsonText,
+				bigRatType:   jsonText,
+				floatType:    jsonText,
+				bigFloatType: jsonText,
+				intervalType: jsonText,
+				complexType:  jsonText,
+				vectorType:   jsonText,
+				matrixType:   jsonText,
+			},
+		},
+
+		{
+			name: "unjson",
+			fn: [numType]unaryFn{
+				charType:   unjson,
+				vectorType: unjson,
+			},
+		},
+
+		{
+			name: "exit",
+			fn: [numType]unaryFn{
+				intType: func(c Context, v Value) Value {
+					i := v.(Int)
+					if i < 0 || i > 255 {
+						Errorf("exit: status %d out of range", i)
+					}
+					panic(Exit(i))
+				},
+			},
+		},
+
+		{
+			name: "read",
+			fn: [numType]unaryFn{
+				charType:   read,
+				vectorType: read,
+			},
+		},
+
+		{
+			name: "lines",
+			fn: [numType]unaryFn{
+				charType:   lines,
+				vectorType: lines,
+			},
+		},
+
+		{
+			name: "dir",
+			fn: [numType]unaryFn{
+				charType:   dir,
+				vectorType: dir,
+			},
+		},
+
 		{
 			name:        "float",
 			elementwise: true,
@@ -944,6 +1231,7 @@ func init() {
 				intType:      floatSelf,
 				bigIntType:   floatSelf,
 				bigRatType:   floatSelf,
+				floatType:    floatSelf,
 				bigFloatType: floatSelf,
 				complexType: func(c Context, v Value) Value {
 					z := v.(Complex)
@@ -951,6 +1239,77 @@ func init() {
 				},
 			},
 		},
+
+		{
+			name:        "interval",
+			elementwise: true,
+			fn: [numType]unaryFn{
+				intType:      func(c Context, v Value) Value { return intervalOf(c, v) },
+				bigIntType:   func(c Context, v Value) Value { return intervalOf(c, v) },
+				bigRatType:   func(c Context, v Value) Value { return intervalOf(c, v) },
+				floatType:    func(c Context, v Value) Value { return intervalOf(c, v) },
+				bigFloatType: func(c Context, v Value) Value { return intervalOf(c, v) },
+				intervalType: self,
+			},
+		},
+
+		{
+			name:        "lower",
+			elementwise: true,
+			fn: [numType]unaryFn{
+				intType:      self,
+				bigIntType:   self,
+				bigRatType:   self,
+				floatType:    self,
+				bigFloatType: self,
+				intervalType: func(c Context, v Value) Value {
+					return BigFloat{newFloat(c).Set(v.(Interval).lo)}.shrink()
+				},
+			},
+		},
+
+		{
+			name:        "upper",
+			elementwise: true,
+			fn: [numType]unaryFn{
+				intType:      self,
+				bigIntType:   self,
+				bigRatType:   self,
+				floatType:    self,
+				bigFloatType: self,
+				intervalType: func(c Context, v Value) Value {
+					return BigFloat{newFloat(c).Set(v.(Interval).hi)}.shrink()
+				},
+			},
+		},
+
+		{
+			name:        "certainly",
+			elementwise: true,
+			fn: [numType]unaryFn{
+				intType:      certainly,
+				bigIntType:   certainly,
+				bigRatType:   certainly,
+				floatType:    certainly,
+				bigFloatType: certainly,
+				intervalType: certainly,
+				complexType:  certainly,
+			},
+		},
+
+		{
+			name:        "possibly",
+			elementwise: true,
+			fn: [numType]unaryFn{
+				intType:      possibly,
+				bigIntType:   possibly,
+				bigRatType:   possibly,
+				floatType:    possibly,
+				bigFloatType: possibly,
+				intervalType: possibly,
+				complexType:  possibly,
+			},
+		},
 	}
 
 	for _, op := range ops {
@@ -49,18 +49,44 @@ func Errorf(format string, args ...interface{}) {
 	panic(Error(fmt.Sprintf(format, args...)))
 }
 
+// Exit is the value with which the exit operator panics. It is not an
+// Error, so it unwinds all evaluation; the program should then exit
+// with the status it holds.
+type Exit int
+
+func (e Exit) Error() string {
+	return fmt.Sprintf("exit %d", int(e))
+}
+
 func Parse(conf *config.Config, s string) (Value, error) {
+	v, err := parse(conf, s)
+	if err != nil || !conf.Machine() {
+		return v, err
+	}
+	// In machine mode, numbers that are not int64s are float64s.
+	switch x := v.(type) {
+	case BigInt:
+		if !x.IsInt64() {
+			v = x.toType("parse", conf, floatType)
+		}
+	case BigRat:
+		v = x.toType("parse", conf, floatType)
+	}
+	return v, nil
+}
+
+func parse(conf *config.Config, s string) (Value, error) {
 	// Is it a rational? If so, it's tricky.
 	if strings.ContainsRune(s, '/') {
 		elems := strings.Split(s, "/")
 		if len(elems) != 2 {
 			panic("bad rat")
 		}
-		num, err := Parse(conf, elems[0])
+		num, err := parse(conf, elems[0])
 		if err != nil {
 			return nil, err
 		}
-		den, err := Parse(conf, elems[1])
+		den, err := parse(conf, elems[1])
 		if err != nil {
 			return nil, err
 		}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"unicode/utf8"
 
 	"robpike.io/ivy/config"
 )
@@ -43,7 +44,7 @@ func (v Vector) makeString(conf *config.Config, spaces bool) string {
 		if spaces && i > 0 {
 			fmt.Fprint(&b, " ")
 		}
-		fmt.Fprintf(&b, "%s", elem.Sprint(conf))
+		fmt.Fprintf(&b, "%s", elemString(conf, elem))
 	}
 	return b.String()
 }
@@ -80,6 +81,38 @@ func NewIntVector(elems []int) Vector {
 	return Vector(vec)
 }
 
+// NewCharVector returns a Vector of Chars holding the runes of s.
+func NewCharVector(s string) Vector {
+	vec := make([]Value, 0, len(s))
+	for _, r := range s {
+		vec = append(vec, Char(r))
+	}
+	return Vector(vec)
+}
+
+// NewCharMatrix returns a Matrix of Chars whose rows hold the runes
+// of the strings, padded with blanks to the length of the longest.
+func NewCharMatrix(strs []string) *Matrix {
+	width := 0
+	for _, s := range strs {
+		if n := utf8.RuneCountInString(s); n > width {
+			width = n
+		}
+	}
+	data := make([]Value, 0, len(strs)*width)
+	for _, s := range strs {
+		n := 0
+		for _, r := range s {
+			data = append(data, Char(r))
+			n++
+		}
+		for ; n < width; n++ {
+			data = append(data, Char(' '))
+		}
+	}
+	return NewMatrix([]int{len(strs), width}, data)
+}
+
 func (v Vector) Eval(Context) Value {
 	return v
 }
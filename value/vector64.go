@@ -0,0 +1,420 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package value
+
+import (
+	"math"
+
+	"robpike.io/ivy/config"
+)
+
+// Int64Vector and Float64Vector are vectors of machine numbers stored
+// unboxed, one int64 or float64 per element rather than one Value.
+// Vector results computed in machine mode are stored this way when all
+// their elements are machine numbers, and the elementwise arithmetic and
+// comparison operators and reductions then run directly on the slices.
+// Elsewhere they behave as the equivalent Vector, which Boxed returns;
+// their type is "vector". They are never modified once made, so they
+// may be shared freely.
+type Int64Vector []int64
+
+type Float64Vector []float64
+
+func (v Int64Vector) String() string {
+	return "(" + v.Sprint(debugConf) + ")"
+}
+
+func (v Float64Vector) String() string {
+	return "(" + v.Sprint(debugConf) + ")"
+}
+
+func (v Int64Vector) Sprint(conf *config.Config) string {
+	return v.Boxed().Sprint(conf)
+}
+
+func (v Float64Vector) Sprint(conf *config.Config) string {
+	return v.Boxed().Sprint(conf)
+}
+
+func (v Int64Vector) ProgString() string {
+	return v.Boxed().ProgString()
+}
+
+func (v Float64Vector) ProgString() string {
+	return v.Boxed().ProgString()
+}
+
+func (v Int64Vector) Rank() int {
+	return 1
+}
+
+func (v Float64Vector) Rank() int {
+	return 1
+}
+
+func (v Int64Vector) Eval(Context) Value {
+	return v
+}
+
+func (v Float64Vector) Eval(Context) Value {
+	return v
+}
+
+func (v Int64Vector) Inner() Value {
+	return v
+}
+
+func (v Float64Vector) Inner() Value {
+	return v
+}
+
+func (v Int64Vector) toType(op string, conf *config.Config, which valueType) Value {
+	return v.Boxed().toType(op, conf, which)
+}
+
+func (v Float64Vector) toType(op string, conf *config.Config, which valueType) Value {
+	return v.Boxed().toType(op, conf, which)
+}
+
+// Boxed returns v as a Vector. Elements outside the range of Int
+// become BigInts.
+func (v Int64Vector) Boxed() Vector {
+	elems := make(Vector, len(v))
+	for i, x := range v {
+		elems[i] = Int(x).maybeBig()
+	}
+	return elems
+}
+
+// Boxed returns v as a Vector. Integral elements in the range of Int
+// become Ints, as they would as the result of a Float operation.
+func (v Float64Vector) Boxed() Vector {
+	elems := make(Vector, len(v))
+	for i, x := range v {
+		elems[i] = Float(x).shrink()
+	}
+	return elems
+}
+
+// Boxed returns v in the general form that code outside the operators
+// understands: an unboxed vector becomes a Vector and a Complex128
+// becomes a Complex. Other values are returned unchanged.
+func Boxed(v Value) Value {
+	switch v := v.(type) {
+	case Int64Vector:
+		return v.Boxed()
+	case Float64Vector:
+		return v.Boxed()
+	case Complex128:
+		return v.complex()
+	}
+	return v
+}
+
+// boxVector returns v, with an unboxed vector converted to a Vector.
+func boxVector(v Value) Value {
+	switch v := v.(type) {
+	case Int64Vector:
+		return v.Boxed()
+	case Float64Vector:
+		return v.Boxed()
+	}
+	return v
+}
+
+// unbox returns v stored unboxed if all its elements are machine
+// numbers that fit: as an Int64Vector if they are all integers that fit
+// in an int64, or as a Float64Vector if they are Ints and Floats.
+// Otherwise, or if v is empty, it returns v.
+func unbox(v Vector) Value {
+	if len(v) == 0 {
+		return v
+	}
+	ints := make(Int64Vector, len(v))
+	for i, x := range v {
+		n, ok := int64Of(x)
+		if !ok {
+			return unboxFloats(v)
+		}
+		ints[i] = n
+	}
+	return ints
+}
+
+// unboxFloats is the Float64Vector half of unbox.
+func unboxFloats(v Vector) Value {
+	floats := make(Float64Vector, len(v))
+	for i, x := range v {
+		switch x := x.(type) {
+		case Int:
+			floats[i] = float64(x)
+		case Float:
+			floats[i] = float64(x)
+		default:
+			return v
+		}
+	}
+	return floats
+}
+
+// machineOperand returns the elements of v, a machine number or a
+// vector of them, as int64s if they are all integers or else as
+// float64s. It reports whether v qualifies and whether it is a vector.
+func machineOperand(v Value) (ints []int64, floats []float64, isVector, ok bool) {
+	switch x := v.(type) {
+	case Int64Vector:
+		return x, nil, true, true
+	case Float64Vector:
+		return nil, x, true, true
+	case Vector:
+		switch x := unbox(x).(type) {
+		case Int64Vector:
+			return x, nil, true, true
+		case Float64Vector:
+			return nil, x, true, true
+		}
+	case Float:
+		return nil, []float64{float64(x)}, false, true
+	default:
+		if n, ok := int64Of(v); ok {
+			return []int64{n}, nil, false, true
+		}
+	}
+	return nil, nil, false, false
+}
+
+// toFloat64s returns the int64s as float64s.
+func toFloat64s(ints []int64) []float64 {
+	floats := make([]float64, len(ints))
+	for i, x := range ints {
+		floats[i] = float64(x)
+	}
+	return floats
+}
+
+// machineIntOps and machineFloatOps64 implement the binary operators that
+// machineVectorBinary and machineReduce evaluate on int64s and float64s.
+// Int64 arithmetic wraps around. Comparisons yield 1 or 0.
+var machineIntOps = map[string]func(x, y int64) int64{
+	"+":   func(x, y int64) int64 { return x + y },
+	"-":   func(x, y int64) int64 { return x - y },
+	"*":   func(x, y int64) int64 { return x * y },
+	"min": func(x, y int64) int64 { return min64(x, y) },
+	"max": func(x, y int64) int64 { return max64(x, y) },
+	"==":  func(x, y int64) int64 { return bool64(x == y) },
+	"!=":  func(x, y int64) int64 { return bool64(x != y) },
+	"<":   func(x, y int64) int64 { return bool64(x < y) },
+	"<=":  func(x, y int64) int64 { return bool64(x <= y) },
+	">":   func(x, y int64) int64 { return bool64(x > y) },
+	">=":  func(x, y int64) int64 { return bool64(x >= y) },
+}
+
+var machineFloatOps64 = map[string]func(x, y float64) float64{
+	"+":   func(x, y float64) float64 { return x + y },
+	"-":   func(x, y float64) float64 { return x - y },
+	"*":   func(x, y float64) float64 { return x * y },
+	"/":   floatQuo,
+	"min": math.Min,
+	"max": math.Max,
+}
+
+// machineFloatCompare implements the comparisons on float64s.
+var machineFloatCompare = map[string]func(x, y float64) bool{
+	"==": func(x, y float64) bool { return x == y },
+	"!=": func(x, y float64) bool { return x != y },
+	"<":  func(x, y float64) bool { return x < y },
+	"<=": func(x, y float64) bool { return x <= y },
+	">":  func(x, y float64) bool { return x > y },
+	">=": func(x, y float64) bool { return x >= y },
+}
+
+func min64(x, y int64) int64 {
+	if x < y {
+		return x
+	}
+	return y
+}
+
+func max64(x, y int64) int64 {
+	if x > y {
+		return x
+	}
+	return y
+}
+
+func bool64(t bool) int64 {
+	if t {
+		return 1
+	}
+	return 0
+}
+
+// floatQuo returns x/y, which must be finite.
+func floatQuo(x, y float64) float64 {
+	if y == 0 {
+		Errorf("division by zero")
+	}
+	return float64(checkFloat(x / y))
+}
+
+// machineVectorBinary evaluates in machine mode the binary operators of
+// machineIntOps and machineFloatOps64 elementwise when either operand is
+// a vector, storing the result unboxed. A single element extends to match
+// the other operand, as in binaryVectorOp. It returns nil if op or the
+// operands do not qualify, and the operation should proceed normally.
+func machineVectorBinary(u Value, op string, v Value) Value {
+	intOp := machineIntOps[op]
+	floatOp, compare := machineFloatOps64[op], machineFloatCompare[op]
+	if intOp == nil && floatOp == nil {
+		return nil
+	}
+	xi, xf, xvec, ok := machineOperand(u)
+	if !ok {
+		return nil
+	}
+	yi, yf, yvec, ok := machineOperand(v)
+	if !ok || !xvec && !yvec {
+		return nil
+	}
+	nx, ny := len(xi)+len(xf), len(yi)+len(yf)
+	n := nx
+	switch {
+	case nx == 1:
+		n = ny
+	case ny == 1:
+	case nx != ny:
+		return nil // Let binaryVectorOp report the mismatch.
+	}
+	// Element i of an operand of length 1 is element 0.
+	ix, iy := 1, 1
+	if nx == 1 {
+		ix = 0
+	}
+	if ny == 1 {
+		iy = 0
+	}
+	if xf == nil && yf == nil && intOp != nil {
+		z := make(Int64Vector, n)
+		for i := range z {
+			z[i] = intOp(xi[i*ix], yi[i*iy])
+		}
+		return z
+	}
+	if xf == nil {
+		xf = toFloat64s(xi)
+	}
+	if yf == nil {
+		yf = toFloat64s(yi)
+	}
+	if compare != nil {
+		z := make(Int64Vector, n)
+		for i := range z {
+			z[i] = bool64(compare(xf[i*ix], yf[i*iy]))
+		}
+		return z
+	}
+	z := make(Float64Vector, n)
+	for i := range z {
+		z[i] = float64(checkFloat(floatOp(xf[i*ix], yf[i*iy])))
+	}
+	return z
+}
+
+// machineUnaryOps64 implement the unary operators that machineVectorUnary
+// evaluates on float64s. Each reports false if its argument is outside
+// the domain that float64 can handle, such as the square root of a
+// negative number, in which case the operation proceeds normally.
+var machineUnaryOps64 = map[string]func(x float64) (float64, bool){
+	"-":     func(x float64) (float64, bool) { return -x, true },
+	"abs":   func(x float64) (float64, bool) { return math.Abs(x), true },
+	"floor": func(x float64) (float64, bool) { return math.Floor(x), true },
+	"ceil":  func(x float64) (float64, bool) { return math.Ceil(x), true },
+	"/":     func(x float64) (float64, bool) { return 1 / x, x != 0 },
+	"**":    func(x float64) (float64, bool) { return math.Exp(x), true },
+	"sqrt":  func(x float64) (float64, bool) { return math.Sqrt(x), x >= 0 },
+	"log":   func(x float64) (float64, bool) { return math.Log(x), x > 0 },
+	"sin":   func(x float64) (float64, bool) { return math.Sin(x), true },
+	"cos":   func(x float64) (float64, bool) { return math.Cos(x), true },
+	"tan":   func(x float64) (float64, bool) { return math.Tan(x), true },
+}
+
+// machineVectorUnary evaluates in machine mode the unary operators of
+// machineUnaryOps64 elementwise on a vector, storing the result unboxed.
+// Negation, abs, floor and ceil of integers stay integers; the other
+// operators compute in float64. It returns nil if op or the operand do
+// not qualify, and the operation should proceed normally.
+func machineVectorUnary(op string, v Value) Value {
+	fn := machineUnaryOps64[op]
+	if fn == nil {
+		return nil
+	}
+	xi, xf, isVector, ok := machineOperand(v)
+	if !ok || !isVector {
+		return nil
+	}
+	if xf == nil {
+		switch op {
+		case "-", "abs", "floor", "ceil":
+			z := make(Int64Vector, len(xi))
+			for i, x := range xi {
+				if op == "-" || op == "abs" && x < 0 {
+					x = -x
+				}
+				z[i] = x
+			}
+			return z
+		}
+		xf = toFloat64s(xi)
+	}
+	z := make(Float64Vector, len(xf))
+	for i, x := range xf {
+		y, ok := fn(x)
+		if !ok {
+			return nil
+		}
+		z[i] = float64(checkFloat(y))
+	}
+	return z
+}
+
+// machineReduce computes in machine mode the reduction of the unboxed
+// vector v by the arithmetic operators of machineIntOps and
+// machineFloatOps64, right to left as Reduce does. It returns nil if op
+// or v do not qualify, and the reduction should proceed normally.
+func machineReduce(op string, v Value) Value {
+	if _, ok := machineFloatCompare[op]; ok {
+		return nil // Comparisons mix their 0 and 1 results with the elements.
+	}
+	var xi []int64
+	var xf []float64
+	switch v := v.(type) {
+	case Int64Vector:
+		xi = v
+	case Float64Vector:
+		xf = v
+	}
+	if len(xi)+len(xf) == 0 {
+		return nil
+	}
+	if intOp := machineIntOps[op]; xi != nil && intOp != nil {
+		acc := xi[len(xi)-1]
+		for i := len(xi) - 2; i >= 0; i-- {
+			acc = intOp(xi[i], acc)
+		}
+		return Int(acc).maybeBig()
+	}
+	floatOp := machineFloatOps64[op]
+	if floatOp == nil {
+		return nil
+	}
+	if xf == nil {
+		xf = toFloat64s(xi)
+	}
+	acc := xf[len(xf)-1]
+	for i := len(xf) - 2; i >= 0; i-- {
+		acc = float64(checkFloat(floatOp(xf[i], acc)))
+	}
+	return Float(acc).shrink()
+}